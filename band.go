@@ -0,0 +1,178 @@
+package flexwriter
+
+import "errors"
+
+// SetBands enables or disables the bands mode. In this mode, when the columns
+// can't fit in the target width, e.g. because of their min widths, they are
+// split into bands, i.e. groups of consecutive columns that fit, written one
+// below another and separated by an empty line. The key columns, indexed as
+// written (including the omitted columns, starting at 0) as with
+// [Writer.SetColumn], are repeated at the start of each band so that the rows
+// of the bands can be matched, e.g. an ID column. See also
+// [Writer.SetSplitMarkers]. By default, the bands mode is disabled.
+func (w *Writer) SetBands(enabled bool, keys ...int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.bands = enabled
+	w.bandKeys = append([]int(nil), keys...)
+}
+
+// flushBands flushes the buffered rows in bands, see SetBands.
+func (w *Writer) flushBands() (FlushStats, error) {
+	rows := w.rows
+	var n int
+	for _, r := range rows {
+		if len(r.cells) > n {
+			n = len(r.cells)
+		}
+	}
+
+	var keys, others []int
+	for ci := 0; ci < n; ci++ {
+		if w.isBandKey(ci) {
+			keys = append(keys, ci)
+		} else {
+			others = append(others, ci)
+		}
+	}
+
+	// the rows are kept only if the output fails before anything is written
+	var kept bool
+	w.inBand = true
+	defer func() {
+		w.inBand = false
+		w.rows = nil
+		if kept {
+			w.rows = rows
+		}
+	}()
+
+	// the bands are laid out as they are flushed, e.g. with the priorities
+	// and the order of their columns
+	fits := func(cols []int) (bool, error) {
+		defer w.selectColumns(rows, cols)()
+		l, restore, err := w.resolveLayout()
+		restore()
+		if err != nil {
+			return false, err
+		}
+		return totalWidth(l.deco, l.outerWidths()) <= w.targetWidth(), nil
+	}
+
+	// the bands are filled greedily, each with at least one column besides
+	// the keys
+	var bands [][]int
+	band := append([]int(nil), keys...)
+	for _, ci := range others {
+		candidate := append(append([]int(nil), band...), ci)
+		ok, err := fits(candidate)
+		if err != nil {
+			return FlushStats{}, err
+		}
+		if !ok && len(band) > len(keys) {
+			bands = append(bands, band)
+			candidate = append(append([]int(nil), keys...), ci)
+		}
+		band = candidate
+	}
+	bands = append(bands, band)
+
+	var stats FlushStats
+	writeError := func(err error) error {
+		return &WriteError{Rows: stats.Rows, Lines: stats.Lines, Err: err}
+	}
+	widths := make([]int, n)
+	for bi, band := range bands {
+		if bi > 0 {
+			if _, err := w.writeOutput([]byte("\n")); err != nil {
+				return FlushStats{}, writeError(err)
+			}
+			stats.Lines++
+			lines, err := w.writeSplitMarker(w.splitMarkers.Header)
+			if err != nil {
+				return FlushStats{}, writeError(err)
+			}
+			stats.Lines += lines
+		}
+		restore := w.selectColumns(rows, band)
+		bandStats, err := w.flush()
+		restore()
+		var werr *WriteError
+		if errors.As(err, &werr) {
+			werr.Lines += stats.Lines
+			werr.Kept = werr.Kept && bi == 0
+			kept = werr.Kept
+		}
+		if err != nil {
+			return FlushStats{}, err
+		}
+		if bi < len(bands)-1 {
+			lines, err := w.writeSplitMarker(w.splitMarkers.Footer)
+			if err != nil {
+				return FlushStats{}, writeError(err)
+			}
+			stats.Lines += lines
+		}
+		stats.Rows = bandStats.Rows
+		stats.Lines += bandStats.Lines
+		stats.Wrapped += bandStats.Wrapped
+		stats.Hidden += bandStats.Hidden
+		for i, ci := range band {
+			if i < len(bandStats.Widths) {
+				widths[ci] = bandStats.Widths[i]
+			}
+		}
+	}
+	stats.Widths = widths
+	return stats, nil
+}
+
+// isBandKey returns whether the visible column of the given index is a key
+// column of the bands.
+func (w *Writer) isBandKey(colIdx int) bool {
+	written := w.writtenIndex(colIdx)
+	for _, key := range w.bandKeys {
+		if key == written {
+			return true
+		}
+	}
+	return false
+}
+
+// selectColumns sets the buffered rows to the given rows, restricted to the
+// given visible columns, and restricts the configuration of the columns
+// accordingly, marking them with their index as written in all the columns;
+// it returns a function that restores the configuration.
+func (w *Writer) selectColumns(rows []row, cols []int) (restore func()) {
+	w.rows = make([]row, len(rows))
+	for i, r := range rows {
+		if !r.rule && len(r.cells) > 0 {
+			cells := make([]string, len(cols))
+			opts := make([]cellOpts, len(cols))
+			for j, ci := range cols {
+				if ci < len(r.cells) {
+					cells[j] = r.cells[ci]
+				}
+				opts[j] = r.cellOpts(ci)
+			}
+			r.cells, r.opts = cells, opts
+			r.spans = selectSpans(r.spans, cols)
+		}
+		w.rows[i] = r
+	}
+
+	columns, fixedWidths := w.columns, w.fixedWidths
+	w.columns = make([]flexItem, len(cols))
+	w.fixedWidths = make([]int, len(cols))
+	for j, ci := range cols {
+		w.columns[j] = w.getColumnDefAt(columns, ci)
+		w.columns[j].written = w.writtenIndex(ci)
+		if ci < len(fixedWidths) {
+			w.fixedWidths[j] = fixedWidths[ci]
+		}
+	}
+	return func() {
+		w.columns, w.fixedWidths = columns, fixedWidths
+	}
+}
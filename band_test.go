@@ -0,0 +1,78 @@
+package flexwriter
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBands(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Rigid{}, Rigid{}, Rigid{})
+	writer.SetBands(true, 0)
+	writer.SetWidth(25)
+
+	writer.WriteRow("id", "first name", "last name", "email")
+	writer.WriteRow("1", "alice", "liddell", "alice@example.com")
+	stats, err := writer.FlushStats()
+	assert.NoError(t, err)
+
+	assert.Equal(t, ""+
+		"id  first name  last name\n"+
+		"1   alice       liddell\n"+
+		"\n"+
+		"id  email\n"+
+		"1   alice@example.com\n", buf.String())
+	assert.Equal(t, 5, stats.Lines)
+	assert.Equal(t, []int{2, 10, 9, 17}, stats.Widths)
+
+	buf.Reset()
+	writer.SetSplitMarkers(SplitMarkers{Header: "(continued)", Footer: "(continued below)"})
+	writer.WriteRow("1", "alice", "liddell", "alice@example.com")
+	writer.Flush()
+	assert.Equal(t, ""+
+		"1  alice  liddell\n"+
+		"(continued below)\n"+
+		"\n"+
+		"(continued)\n"+
+		"1  alice@example.com\n", buf.String())
+
+	// the bands are not used when the columns fit
+	buf.Reset()
+	writer.SetWidth(80)
+	writer.WriteRow("1", "alice", "liddell", "alice@example.com")
+	writer.Flush()
+	assert.Equal(t, "1  alice  liddell  alice@example.com\n", buf.String())
+}
+
+func TestBandsWrittenColumns(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetHeaderStyle(nil)
+	writer.SetColumns(Rigid{}, Rigid{}, Rigid{}, Rigid{})
+	writer.SetBands(true, 0)
+	writer.SetWidth(25)
+	writer.SetHeaders("id", "first name", "last name", "email")
+	writer.HighlightRegexp(regexp.MustCompile("@"), color.New(color.BgYellow), 3)
+
+	writer.WriteRow("1", "alice", "liddell", "alice@example.com")
+	writer.Flush()
+
+	// the columns are indexed as written in all the bands
+	assert.Equal(t, ""+
+		"id  first name  last name\n"+
+		"1   alice       liddell\n"+
+		"\n"+
+		"id  email\n"+
+		"1   alice\x1b[43m@\x1b[0mexample.com\n", buf.String())
+}
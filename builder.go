@@ -0,0 +1,78 @@
+package flexwriter
+
+import "github.com/fatih/color"
+
+// RowBuilder builds a row cell by cell, which allows setting options on
+// individual cells. It is created by [Writer.AddRow].
+type RowBuilder struct {
+	w     *Writer
+	cells []any
+	opts  []cellOpts
+	done  bool // whether the row was written, after which the builder is inert
+}
+
+// AddRow starts building a row, as an alternative to [Writer.WriteRow] when
+// some of the cells need options:
+//
+//	writer.AddRow().
+//		Cell("total").
+//		Cell(42).Align(flexwriter.Right).Style(color.New(color.Bold)).
+//		Done()
+//
+// The row is only written to the flex writer when [RowBuilder.Done] is called;
+// the builder can't be reused after that, its methods then do nothing.
+func (w *Writer) AddRow() *RowBuilder {
+	return &RowBuilder{w: w}
+}
+
+// Cell adds a cell to the row. If the value is not a string, it is converted
+// to a string using [fmt.Sprint].
+func (b *RowBuilder) Cell(value any) *RowBuilder {
+	if b.done {
+		return b
+	}
+	b.cells = append(b.cells, value)
+	b.opts = append(b.opts, cellOpts{})
+	return b
+}
+
+// Align sets the alignment of the last cell added, overriding the alignment of
+// its column; it does nothing if no cell was added yet.
+func (b *RowBuilder) Align(align Alignment) *RowBuilder {
+	if last := b.last(); last != nil {
+		last.align = &align
+	}
+	return b
+}
+
+// Style sets the style of the content of the last cell added; it does nothing
+// if no cell was added yet.
+func (b *RowBuilder) Style(style *color.Color) *RowBuilder {
+	if last := b.last(); last != nil {
+		last.style = newStyle(style)
+	}
+	return b
+}
+
+// last returns the options of the last cell added, or nil if there is none or
+// if the row was written.
+func (b *RowBuilder) last() *cellOpts {
+	if len(b.opts) == 0 || b.done {
+		return nil
+	}
+	return &b.opts[len(b.opts)-1]
+}
+
+// Done writes the row to the flex writer; calling it again does nothing.
+func (b *RowBuilder) Done() {
+	if b.done {
+		return
+	}
+	b.done = true
+
+	b.w.mu.Lock()
+	defer b.w.mu.Unlock()
+
+	b.w.writeRowOpts(b.cells, b.opts)
+	b.w.streamRows()
+}
@@ -0,0 +1,42 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowBuilder(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDefaultColumn(Rigid{})
+	writer.SetColumns(Omit{})
+
+	writer.AddRow().Cell("omitted").Cell("item").Cell("price").Done()
+	writer.AddRow().
+		Cell("x").
+		Cell("apple").Align(Right).
+		Cell(3).Align(Right).Style(color.New(color.Bold)).
+		Done()
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"item   price\n"+
+		"apple  \x1b[1m    3\x1b[22m\n", buf.String())
+
+	// the options without a cell and the reuse of the builder are ignored
+	buf.Reset()
+	builder := writer.AddRow().Align(Right).Cell("x").Cell("kiwi").Cell(1)
+	builder.Done()
+	builder.Cell("ignored").Done()
+	writer.Flush()
+
+	assert.Equal(t, "kiwi  1\n", buf.String())
+}
@@ -0,0 +1,50 @@
+package flexwriter
+
+import (
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// Caption is a text written above or below the rows, e.g. a title.
+type Caption struct {
+	// Text is the text of the caption; it is wrapped if it is wider than the
+	// output. If it is empty, there is no caption.
+	Text string
+	// Below places the caption below the rows instead of above them.
+	Below bool
+	// Align is the alignment of the caption relative to the width of the
+	// output, including the decorator; default is left.
+	Align Alignment
+}
+
+// SetCaption sets a caption that is written above or below the rows on every
+// flush, as long as there is at least one row.
+func (w *Writer) SetCaption(caption Caption) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.caption = caption
+}
+
+// captionLines returns the lines of the caption, wrapped and aligned to the
+// width of the output.
+func (w *Writer) captionLines(l layout) ([]string, error) {
+	if w.caption.Text == "" || (len(w.rows) == 0 && !w.streamContinues()) {
+		return nil, nil
+	}
+	outer := l.outerWidths()
+	if w.streamContinues() {
+		// the caption ends a stream, whose widths are frozen
+		outer = w.stream.outer
+	}
+	width := totalWidth(l.deco, outer)
+	if width < 1 {
+		width = textutil.Width(w.caption.Text)
+	}
+	lines, err := textutil.Wrap(w.caption.Text, width)
+	if err != nil {
+		return nil, err
+	}
+	return transform(lines, func(line string) string {
+		return textutil.Align(line, width, w.caption.Align, false)
+	}), nil
+}
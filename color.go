@@ -0,0 +1,94 @@
+package flexwriter
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// ColorMode is whether the styles of a flex writer are written to its output,
+// see [Writer.SetColorMode].
+type ColorMode int
+
+const (
+	// ColorAuto writes the styles unless the NO_COLOR environment variable is
+	// set, or [color.NoColor] is set (which is the case if the standard
+	// output is not a terminal), or the output is a file that is not a
+	// terminal. This is the default.
+	ColorAuto ColorMode = iota
+	// ColorNever never writes the styles.
+	ColorNever
+	// ColorAlways always writes the styles, e.g. to force colors in a pipe to
+	// a pager that supports them.
+	ColorAlways
+)
+
+// SetColorMode sets whether the styles of the flex writer are written to its
+// output: the colors of the decorator (see [ColorizeDecorator] and
+// [GradientDecorator]), and the styles of the cells, rows and columns, e.g.
+// those set with [Writer.SetTheme]. The escape sequences within the content
+// of the cells are left as they are. The mode applies when flushing, so that
+// it can be set at any time; the default is [ColorAuto].
+func (w *Writer) SetColorMode(mode ColorMode) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.colorMode = mode
+}
+
+// colorsEnabled returns whether the styles are written to the output.
+func (w *Writer) colorsEnabled() bool {
+	switch w.colorMode {
+	case ColorNever:
+		return false
+	case ColorAlways:
+		return true
+	}
+	if color.NoColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if _, ok := w.output.(*os.File); ok {
+		detect := w.termWidth
+		if detect == nil {
+			detect = TerminalWidth
+		}
+		_, isTerminal := detect(w.output)
+		return isTerminal
+	}
+	return true
+}
+
+// noColorDecorator wraps a decorator to remove the escape sequences, e.g. the
+// colors, from its separators.
+type noColorDecorator struct {
+	parent Decorator
+}
+
+func (d noColorDecorator) RowSeparator(rowIdx int, widths []int) string {
+	return d.strip(d.parent.RowSeparator(rowIdx, widths))
+}
+
+func (d noColorDecorator) ColumnSeparator(rowIdx, colIdx int) string {
+	return d.strip(d.parent.ColumnSeparator(rowIdx, colIdx))
+}
+
+func (d noColorDecorator) RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string {
+	return d.strip(rowSeparator(d.parent, rowIdx, above, below, widths))
+}
+
+func (d noColorDecorator) RowSeparatorSpan(rowIdx int, above, below RowKind, widths []int, joinedAbove, joinedBelow []bool) string {
+	return d.strip(rowSeparatorSpan(d.parent, rowIdx, above, below, widths, joinedAbove, joinedBelow))
+}
+
+func (d noColorDecorator) RuleSeparator(rowIdx int, above, below RowKind, widths []int) string {
+	return d.strip(ruleSeparator(d.parent, rowIdx, above, below, widths))
+}
+
+func (d noColorDecorator) ColumnSeparatorKind(rowIdx, colIdx int, kind RowKind) string {
+	return d.strip(columnSeparator(d.parent, rowIdx, colIdx, kind))
+}
+
+func (d noColorDecorator) strip(s string) string {
+	return textutil.StripEscapes(s)
+}
@@ -0,0 +1,67 @@
+package flexwriter
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorMode(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	write := func(writer *Writer) {
+		writer.SetDecorator(ColorizeDecorator(GapDecorator{Gap: "|"}, color.New(color.FgRed)))
+		writer.SetColumns(Rigid{Style: color.New(color.Bold)}, Rigid{})
+		writer.WriteRow("a", "\x1b[32mb\x1b[0m")
+		writer.Flush()
+	}
+	colored := "\x1b[1ma\x1b[22m\x1b[31m|\x1b[0m\x1b[32mb\x1b[0m\n"
+	plain := "a|\x1b[32mb\x1b[0m\n"
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	write(writer)
+	assert.Equal(t, colored, buf.String())
+
+	// the escape sequences of the content are kept
+	buf.Reset()
+	writer.SetColorMode(ColorNever)
+	write(writer)
+	assert.Equal(t, plain, buf.String())
+
+	buf.Reset()
+	color.NoColor = true
+	writer.SetColorMode(ColorAuto)
+	write(writer)
+	assert.Equal(t, plain, buf.String())
+
+	buf.Reset()
+	writer.SetColorMode(ColorAlways)
+	write(writer)
+	assert.Equal(t, colored, buf.String())
+
+	buf.Reset()
+	color.NoColor = false
+	t.Setenv("NO_COLOR", "1")
+	writer.SetColorMode(ColorAuto)
+	write(writer)
+	assert.Equal(t, plain, buf.String())
+	os.Unsetenv("NO_COLOR")
+
+	// a file is not a terminal
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer f.Close()
+	writer = New()
+	writer.SetOutput(f)
+	write(writer)
+	content, err := os.ReadFile(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, plain, string(content))
+}
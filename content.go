@@ -0,0 +1,44 @@
+package flexwriter
+
+// ContentProvider can be implemented by the values of the cells to render
+// custom content, e.g. bars or nested blocks, that takes part in the sizing of
+// the columns like text does.
+type ContentProvider interface {
+	// MinWidth is the minimum width of the content, like the width of the
+	// longest word of a text.
+	MinWidth() int
+	// PreferredWidth is the width of the content if it is not constrained,
+	// like the width of a text that is not wrapped.
+	PreferredWidth() int
+	// Render renders the content at the final width of its column; the lines
+	// must not be wider than width, which may be lower than MinWidth if the
+	// output is too narrow.
+	Render(width int) []string
+}
+
+// renderFunc is the ContentProvider of a func(width int) string cell, which
+// doesn't take part in the sizing.
+type renderFunc func(width int) string
+
+func (f renderFunc) MinWidth() int {
+	return 0
+}
+
+func (f renderFunc) PreferredWidth() int {
+	return 0
+}
+
+func (f renderFunc) Render(width int) []string {
+	return []string{f(width)}
+}
+
+// contentProvider returns the ContentProvider of a cell value, if it has one.
+func contentProvider(value any) (ContentProvider, bool) {
+	switch v := value.(type) {
+	case ContentProvider:
+		return v, true
+	case func(width int) string:
+		return renderFunc(v), true
+	}
+	return nil, false
+}
@@ -0,0 +1,46 @@
+package flexwriter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gauge is a bar of 2 lines, that prefers to be 10 cells wide but can shrink
+// down to 4.
+type gauge struct {
+	ratio float64
+}
+
+func (g gauge) MinWidth() int       { return 4 }
+func (g gauge) PreferredWidth() int { return 10 }
+
+func (g gauge) Render(width int) []string {
+	filled := int(g.ratio * float64(width))
+	return []string{
+		strings.Repeat("#", filled) + strings.Repeat(".", width-filled),
+		strings.Repeat("-", width),
+	}
+}
+
+func TestContentProvider(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Shrinkable{})
+
+	writer.WriteRow("disk", gauge{0.5})
+	writer.Flush()
+
+	writer.SetWidth(10)
+	writer.WriteRow("disk", gauge{0.5})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"disk  #####.....\n"+
+		"      ----------\n"+
+		"disk  ##..\n"+
+		"      ----\n", buf.String())
+}
@@ -5,6 +5,8 @@ import (
 
 	text "github.com/MichaelMure/go-term-text"
 	"github.com/fatih/color"
+	"github.com/hchargois/flexwriter/flex"
+	"github.com/hchargois/flexwriter/textutil"
 )
 
 // Decorator is used to decorate the output. It can be used to add spacing
@@ -28,6 +30,114 @@ type Decorator interface {
 	ColumnSeparator(rowIdx, colIdx int) string
 }
 
+// RowKind is the kind of a row, i.e. its role in the structure of the output.
+type RowKind int
+
+const (
+	// NoRow is used as the kind of the row above the top border, or below the
+	// bottom border.
+	NoRow RowKind = iota - 1
+	// BodyRow is a regular row of data.
+	BodyRow
+	// HeaderRow is a row of column titles.
+	HeaderRow
+	// FooterRow is a row of e.g. totals, after the body rows, see
+	// [Writer.WriteFooter].
+	FooterRow
+	// SectionRow is a row that introduces a group of rows.
+	SectionRow
+	// SpacerRow is an empty row used as vertical spacing, see [EmptyRowSpacer].
+	SpacerRow
+)
+
+// KindDecorator is a [Decorator] that is also told the kind of the rows it
+// decorates, so that it can e.g. draw a different separator below the header.
+// If the decorator of a writer implements this interface, its RowSeparatorKind
+// and ColumnSeparatorKind methods are used instead of RowSeparator and
+// ColumnSeparator.
+type KindDecorator interface {
+	Decorator
+
+	// RowSeparatorKind is like RowSeparator; above and below are the kinds of
+	// the rows above and below the separator, NoRow for the top and bottom
+	// borders.
+	RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string
+
+	// ColumnSeparatorKind is like ColumnSeparator; kind is the kind of the
+	// row. The width of the separators must not depend on the kind.
+	ColumnSeparatorKind(rowIdx, colIdx int, kind RowKind) string
+}
+
+// SpanDecorator is a [KindDecorator] that can also draw the row separators
+// next to the cells spanning several columns, e.g. the headers of the column
+// groups (see [Writer.SetColumnGroups]), without the intersections of the
+// column separators that are missing there. If the decorator of a writer
+// implements this interface, its RowSeparatorSpan method is used instead of
+// RowSeparatorKind for the separators next to such cells.
+type SpanDecorator interface {
+	KindDecorator
+
+	// RowSeparatorSpan is like RowSeparatorKind; joinedAbove and joinedBelow
+	// tell whether each boundary between two columns, indexed as in
+	// ColumnSeparator (from 1 to len(widths)-1), is inside a cell spanning
+	// several columns in the row above or below the separator; they are nil
+	// if there is no such cell.
+	RowSeparatorSpan(rowIdx int, above, below RowKind, widths []int, joinedAbove, joinedBelow []bool) string
+}
+
+// RuleDecorator is a [Decorator] that also draws the separators written with
+// [Writer.WriteSeparator], e.g. with its own borders rather than the default
+// line of "─". If the decorator of a writer implements this interface, its
+// RuleSeparator method is used for these separators.
+type RuleDecorator interface {
+	Decorator
+
+	// RuleSeparator returns the separator written with WriteSeparator below
+	// the row of the given index, as in RowSeparator (0 above the first row);
+	// above and below are the kinds of the rows around it, NoRow if there is
+	// none. It is only called if the decorator doesn't already separate these
+	// rows; an empty string writes nothing.
+	RuleSeparator(rowIdx int, above, below RowKind, widths []int) string
+}
+
+// ruleSeparator calls the RuleSeparator method of the decorator if it is a
+// RuleDecorator, or returns a line of "─" of the width of the output
+// otherwise.
+func ruleSeparator(deco Decorator, rowIdx int, above, below RowKind, widths []int) string {
+	if rd, ok := deco.(RuleDecorator); ok {
+		return rd.RuleSeparator(rowIdx, above, below, widths)
+	}
+	return strings.Repeat("─", totalWidth(deco, widths))
+}
+
+// rowSeparatorSpan calls the RowSeparatorSpan method of the decorator if it is
+// a SpanDecorator and there are spanning cells next to the separator, or
+// rowSeparator otherwise.
+func rowSeparatorSpan(deco Decorator, rowIdx int, above, below RowKind, widths []int, joinedAbove, joinedBelow []bool) string {
+	if sd, ok := deco.(SpanDecorator); ok && (joinedAbove != nil || joinedBelow != nil) {
+		return sd.RowSeparatorSpan(rowIdx, above, below, widths, joinedAbove, joinedBelow)
+	}
+	return rowSeparator(deco, rowIdx, above, below, widths)
+}
+
+// rowSeparator calls the RowSeparatorKind method of the decorator if it is a
+// KindDecorator, or its RowSeparator method otherwise.
+func rowSeparator(deco Decorator, rowIdx int, above, below RowKind, widths []int) string {
+	if kd, ok := deco.(KindDecorator); ok {
+		return kd.RowSeparatorKind(rowIdx, above, below, widths)
+	}
+	return deco.RowSeparator(rowIdx, widths)
+}
+
+// columnSeparator calls the ColumnSeparatorKind method of the decorator if it
+// is a KindDecorator, or its ColumnSeparator method otherwise.
+func columnSeparator(deco Decorator, rowIdx, colIdx int, kind RowKind) string {
+	if kd, ok := deco.(KindDecorator); ok {
+		return kd.ColumnSeparatorKind(rowIdx, colIdx, kind)
+	}
+	return deco.ColumnSeparator(rowIdx, colIdx)
+}
+
 // GapDecorator is a simple decorator that adds a fixed gap between each column,
 // as well as a left gap (before the left-most column) and a right gap (after the
 // right-most column).
@@ -60,26 +170,122 @@ type TableDecorator struct {
 	BottomIntersections [3]string
 	VertBorders         [3]string
 	HorizBorders        [3]string // (top, middle, bottom), must be of width 1, will be repeated as needed
+
+	// HeaderIntersections and HeaderBorder are used instead of the middle
+	// ones for the separators below the header rows (see [Writer.SetHeaders])
+	// and above the footer rows (see [Writer.WriteFooter]), if HeaderBorder is
+	// not empty; HeaderBorder must be of width 1.
+	HeaderIntersections [3]string
+	HeaderBorder        string
+
+	// Boundaries overrides the borders of some of the boundaries between two
+	// columns; the key is the index of the column on the left of the boundary,
+	// i.e. 1 for the boundary between the first and the second columns.
+	Boundaries map[int]Boundary
+}
+
+// Boundary is the vertical border of a boundary between two columns of a
+// [TableDecorator], along with its (top, middle, bottom) intersections. They
+// should all be of the same width.
+type Boundary struct {
+	Intersections [3]string
+	VertBorder    string
 }
 
-func (d TableDecorator) rowSep(intersects [3]string, horiz string, widths []int) string {
-	borders := transform(widths, func(w int) string {
-		return strings.Repeat(horiz, w)
-	})
-	return intersects[0] +
-		strings.Join(borders, intersects[1]) +
-		intersects[2]
+func (d TableDecorator) rowSep(pos int, intersects [3]string, horiz string, widths []int) string {
+	return d.rowSepSpan(pos, intersects, horiz, widths, nil, nil)
+}
+
+// rowSepSpan is like rowSep, but the intersections of the boundaries joined
+// above or below the separator are drawn without the missing column
+// separators.
+func (d TableDecorator) rowSepSpan(pos int, intersects [3]string, horiz string, widths []int, joinedAbove, joinedBelow []bool) string {
+	joined := func(joined []bool, i int) bool {
+		return i < len(joined) && joined[i]
+	}
+	var sb strings.Builder
+	sb.WriteString(intersects[0])
+	for i, w := range widths {
+		if i > 0 {
+			// the position of the intersection, as if it were on the top or
+			// bottom border when the boundary is joined below or above
+			at := pos
+			switch a, b := joined(joinedAbove, i), joined(joinedBelow, i); {
+			case (a || pos == 0) && (b || pos == 2):
+				at = -1
+			case a:
+				at = 0
+			case b:
+				at = 2
+			}
+			if at == -1 {
+				sb.WriteString(strings.Repeat(horiz, textutil.Width(intersects[1])))
+			} else if b, ok := d.Boundaries[i]; ok {
+				sb.WriteString(b.Intersections[at])
+			} else if at == pos {
+				sb.WriteString(intersects[1])
+			} else if at == 0 {
+				sb.WriteString(d.TopIntersections[1])
+			} else {
+				sb.WriteString(d.BottomIntersections[1])
+			}
+		}
+		sb.WriteString(strings.Repeat(horiz, w))
+	}
+	sb.WriteString(intersects[2])
+	return sb.String()
 }
 
 func (d TableDecorator) RowSeparator(rowIdx int, widths []int) string {
 	switch rowIdx {
 	case 0:
-		return d.rowSep(d.TopIntersections, d.HorizBorders[0], widths)
+		return d.rowSep(0, d.TopIntersections, d.HorizBorders[0], widths)
 	case -1:
-		return d.rowSep(d.BottomIntersections, d.HorizBorders[2], widths)
+		return d.rowSep(2, d.BottomIntersections, d.HorizBorders[2], widths)
 	default:
-		return d.rowSep(d.MiddleIntersections, d.HorizBorders[1], widths)
+		return d.rowSep(1, d.MiddleIntersections, d.HorizBorders[1], widths)
+	}
+}
+
+// headerBoundary returns whether the separator between rows of the given kinds
+// is drawn with the header border.
+func (d TableDecorator) headerBoundary(above, below RowKind) bool {
+	if d.HeaderBorder == "" {
+		return false
+	}
+	return above == HeaderRow && below != HeaderRow && below != NoRow ||
+		below == FooterRow && above != FooterRow && above != NoRow
+}
+
+func (d TableDecorator) RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string {
+	if d.headerBoundary(above, below) {
+		return d.rowSep(1, d.HeaderIntersections, d.HeaderBorder, widths)
 	}
+	return d.RowSeparator(rowIdx, widths)
+}
+
+func (d TableDecorator) RowSeparatorSpan(rowIdx int, above, below RowKind, widths []int, joinedAbove, joinedBelow []bool) string {
+	if d.headerBoundary(above, below) {
+		// there are no header intersections for the boundaries joined on
+		// one side only, they are drawn as the others
+		joined := make([]bool, len(widths))
+		for i := range joined {
+			joined[i] = i < len(joinedAbove) && joinedAbove[i] && i < len(joinedBelow) && joinedBelow[i]
+		}
+		return d.rowSepSpan(1, d.HeaderIntersections, d.HeaderBorder, widths, joined, joined)
+	}
+	switch rowIdx {
+	case 0:
+		return d.rowSepSpan(0, d.TopIntersections, d.HorizBorders[0], widths, joinedAbove, joinedBelow)
+	case -1:
+		return d.rowSepSpan(2, d.BottomIntersections, d.HorizBorders[2], widths, joinedAbove, joinedBelow)
+	default:
+		return d.rowSepSpan(1, d.MiddleIntersections, d.HorizBorders[1], widths, joinedAbove, joinedBelow)
+	}
+}
+
+func (d TableDecorator) ColumnSeparatorKind(rowIdx, colIdx int, _ RowKind) string {
+	return d.ColumnSeparator(rowIdx, colIdx)
 }
 
 func (d TableDecorator) ColumnSeparator(_, colIdx int) string {
@@ -89,6 +295,9 @@ func (d TableDecorator) ColumnSeparator(_, colIdx int) string {
 	case -1:
 		return d.VertBorders[2]
 	default:
+		if b, ok := d.Boundaries[colIdx]; ok {
+			return b.VertBorder
+		}
 		return d.VertBorders[1]
 	}
 }
@@ -102,6 +311,8 @@ func AsciiTableDecorator() Decorator {
 		BottomIntersections: [3]string{"+-", "-+-", "-+"},
 		VertBorders:         [3]string{"| ", " | ", " |"},
 		HorizBorders:        [3]string{"-", "-", "-"},
+		HeaderIntersections: [3]string{"+=", "=+=", "=+"},
+		HeaderBorder:        "=",
 	}
 }
 
@@ -113,6 +324,45 @@ func BoxDrawingTableDecorator() Decorator {
 		BottomIntersections: [3]string{"└─", "─┴─", "─┘"},
 		VertBorders:         [3]string{"│ ", " │ ", " │"},
 		HorizBorders:        [3]string{"─", "─", "─"},
+		HeaderIntersections: [3]string{"╞═", "═╪═", "═╡"},
+		HeaderBorder:        "═",
+	}
+}
+
+// RoundedTableDecorator creates a table with Unicode box drawing characters
+// and rounded corners.
+func RoundedTableDecorator() Decorator {
+	return &TableDecorator{
+		TopIntersections:    [3]string{"╭─", "─┬─", "─╮"},
+		MiddleIntersections: [3]string{"├─", "─┼─", "─┤"},
+		BottomIntersections: [3]string{"╰─", "─┴─", "─╯"},
+		VertBorders:         [3]string{"│ ", " │ ", " │"},
+		HorizBorders:        [3]string{"─", "─", "─"},
+		HeaderIntersections: [3]string{"╞═", "═╪═", "═╡"},
+		HeaderBorder:        "═",
+	}
+}
+
+// DoubleTableDecorator creates a table with Unicode double box drawing
+// characters.
+func DoubleTableDecorator() Decorator {
+	return &TableDecorator{
+		TopIntersections:    [3]string{"╔═", "═╦═", "═╗"},
+		MiddleIntersections: [3]string{"╠═", "═╬═", "═╣"},
+		BottomIntersections: [3]string{"╚═", "═╩═", "═╝"},
+		VertBorders:         [3]string{"║ ", " ║ ", " ║"},
+		HorizBorders:        [3]string{"═", "═", "═"},
+	}
+}
+
+// MinimalTableDecorator creates a table without borders, whose columns are
+// separated by 2 spaces and whose header rows are underlined with a line of
+// "─", if there are any.
+func MinimalTableDecorator() Decorator {
+	return &TableDecorator{
+		VertBorders:         [3]string{"", "  ", ""},
+		HeaderIntersections: [3]string{"", "──", ""},
+		HeaderBorder:        "─",
 	}
 }
 
@@ -124,30 +374,49 @@ type colorDecorator struct {
 
 // ColorizeDecorator wraps a decorator to make it colorful.
 func ColorizeDecorator(parent Decorator, color *color.Color) Decorator {
-	// fatih/color is very badly designed and is extremely inefficient, but we
-	// can improve the situation by first making it colorize a string, use it
-	// to extract the in and out escape strings, and then use those with simple
-	// concatenation.
-	cut := "__CUT_HERE__"
-	colored := color.Sprint(cut)
-	in, out, _ := strings.Cut(colored, cut)
+	s := newStyle(color)
 	return colorDecorator{
 		parent: parent,
-		in:     in,
-		out:    out,
+		in:     s.in,
+		out:    s.out,
 	}
 }
 
 func (d colorDecorator) RowSeparator(rowIdx int, widths []int) string {
-	return d.in + d.parent.RowSeparator(rowIdx, widths) + d.out
+	return d.colorize(d.parent.RowSeparator(rowIdx, widths))
 }
 
 func (d colorDecorator) ColumnSeparator(rowIdx, colIdx int) string {
-	return d.in + d.parent.ColumnSeparator(rowIdx, colIdx) + d.out
+	return d.colorize(d.parent.ColumnSeparator(rowIdx, colIdx))
+}
+
+func (d colorDecorator) RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string {
+	return d.colorize(rowSeparator(d.parent, rowIdx, above, below, widths))
+}
+
+func (d colorDecorator) ColumnSeparatorKind(rowIdx, colIdx int, kind RowKind) string {
+	return d.colorize(columnSeparator(d.parent, rowIdx, colIdx, kind))
+}
+
+func (d colorDecorator) RowSeparatorSpan(rowIdx int, above, below RowKind, widths []int, joinedAbove, joinedBelow []bool) string {
+	return d.colorize(rowSeparatorSpan(d.parent, rowIdx, above, below, widths, joinedAbove, joinedBelow))
+}
+
+func (d colorDecorator) RuleSeparator(rowIdx int, above, below RowKind, widths []int) string {
+	return d.colorize(ruleSeparator(d.parent, rowIdx, above, below, widths))
+}
+
+func (d colorDecorator) colorize(s string) string {
+	// an empty separator must stay empty, e.g. so that no line is written for
+	// an empty row separator
+	if s == "" {
+		return ""
+	}
+	return d.in + s + d.out
 }
 
 func decoratorWidth(deco Decorator, cols int) int {
-	rlen := text.Len
+	rlen := textutil.Width
 	var w int
 	w += rlen(deco.ColumnSeparator(0, 0))
 	w += rlen(deco.ColumnSeparator(0, -1))
@@ -156,3 +425,73 @@ func decoratorWidth(deco Decorator, cols int) int {
 	}
 	return w
 }
+
+// decoratorContainer returns the container of cols columns decorated with the
+// given decorator, in the given width: its padding and its gaps are the left,
+// right and inner column separators.
+func decoratorContainer(deco Decorator, cols, width int) flex.Container {
+	rlen := textutil.Width
+	c := flex.Container{
+		Size:         width,
+		PaddingLeft:  rlen(deco.ColumnSeparator(0, 0)),
+		PaddingRight: rlen(deco.ColumnSeparator(0, -1)),
+		Gaps:         make([]int, 0, cols),
+	}
+	for i := 1; i < cols; i++ {
+		c.Gaps = append(c.Gaps, rlen(deco.ColumnSeparator(0, i)))
+	}
+	return c
+}
+
+// collapsedDecorator wraps a decorator to remove some cells from its column
+// separators.
+type collapsedDecorator struct {
+	parent   Decorator
+	collapse []int // number of cells removed from each column separator
+}
+
+func (d collapsedDecorator) RowSeparator(rowIdx int, widths []int) string {
+	return d.parent.RowSeparator(rowIdx, widths)
+}
+
+func (d collapsedDecorator) ColumnSeparator(rowIdx, colIdx int) string {
+	return d.collapseSeparator(colIdx, d.parent.ColumnSeparator(rowIdx, colIdx))
+}
+
+func (d collapsedDecorator) RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string {
+	return rowSeparator(d.parent, rowIdx, above, below, widths)
+}
+
+func (d collapsedDecorator) RowSeparatorSpan(rowIdx int, above, below RowKind, widths []int, joinedAbove, joinedBelow []bool) string {
+	return rowSeparatorSpan(d.parent, rowIdx, above, below, widths, joinedAbove, joinedBelow)
+}
+
+func (d collapsedDecorator) RuleSeparator(rowIdx int, above, below RowKind, widths []int) string {
+	if rd, ok := d.parent.(RuleDecorator); ok {
+		return rd.RuleSeparator(rowIdx, above, below, widths)
+	}
+	// the default line is as wide as the collapsed separators
+	return strings.Repeat("─", totalWidth(d, widths))
+}
+
+func (d collapsedDecorator) ColumnSeparatorKind(rowIdx, colIdx int, kind RowKind) string {
+	return d.collapseSeparator(colIdx, columnSeparator(d.parent, rowIdx, colIdx, kind))
+}
+
+func (d collapsedDecorator) collapseSeparator(colIdx int, sep string) string {
+	if colIdx <= 0 || colIdx >= len(d.collapse) || d.collapse[colIdx] == 0 {
+		return sep
+	}
+	stripped, escapes := text.ExtractTermEscapes(sep)
+	return text.ApplyTermEscapes(stripped[d.collapse[colIdx]:], escapes)
+}
+
+// collapsible returns the number of cells that can be removed from a column
+// separator: separators made only of spaces can collapse to a single space.
+func collapsible(sep string) int {
+	stripped, _ := text.ExtractTermEscapes(sep)
+	if stripped == "" || strings.Trim(stripped, " ") != "" {
+		return 0
+	}
+	return len(stripped) - 1
+}
@@ -3,6 +3,7 @@ package flexwriter
 import (
 	"testing"
 
+	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,3 +19,48 @@ func TestDecoratorWidth(t *testing.T) {
 	assert.Equal(t, 6, decoratorWidth(deco, 2))
 	assert.Equal(t, 8, decoratorWidth(deco, 3))
 }
+
+func TestTableDecoratorBoundaries(t *testing.T) {
+	deco := TableDecorator{
+		TopIntersections:    [3]string{"+-", "-+-", "-+"},
+		MiddleIntersections: [3]string{"+-", "-+-", "-+"},
+		BottomIntersections: [3]string{"+-", "-+-", "-+"},
+		VertBorders:         [3]string{"| ", " | ", " |"},
+		HorizBorders:        [3]string{"-", "-", "-"},
+		Boundaries: map[int]Boundary{
+			2: {Intersections: [3]string{"-", ":", "-"}, VertBorder: ":"},
+		},
+	}
+
+	assert.Equal(t, 7, decoratorWidth(deco, 2))
+	assert.Equal(t, 8, decoratorWidth(deco, 3))
+	assert.Equal(t, "+---+-------+", deco.RowSeparator(0, []int{1, 2, 2}))
+	assert.Equal(t, "+---+---:---+", deco.RowSeparator(1, []int{1, 2, 2}))
+	assert.Equal(t, " | ", deco.ColumnSeparator(1, 1))
+	assert.Equal(t, ":", deco.ColumnSeparator(1, 2))
+}
+
+func TestGradientDecorator(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	deco := GradientDecorator(GapDecorator{Left: "|", Gap: "|", Right: "|"}, Gradient{
+		From: RGB{0, 0, 0},
+		To:   RGB{200, 100, 0},
+	})
+	assert.Equal(t, "", deco.RowSeparator(0, []int{2, 2}))
+	assert.Equal(t, "\x1b[38;2;0;0;0m|\x1b[0m", deco.ColumnSeparator(1, 0))
+	assert.Equal(t, "\x1b[38;2;100;50;0m|\x1b[0m", deco.ColumnSeparator(1, 1))
+	assert.Equal(t, "\x1b[38;2;200;100;0m|\x1b[0m", deco.ColumnSeparator(1, -1))
+
+	deco = GradientDecorator(AsciiTableDecorator(), Gradient{
+		From:     RGB{0, 0, 0},
+		To:       RGB{200, 100, 0},
+		Vertical: true,
+		Rows:     4,
+	})
+	assert.Equal(t, "\x1b[38;2;0;0;0m+---+\x1b[0m", deco.RowSeparator(0, []int{1}))
+	assert.Equal(t, "\x1b[38;2;100;50;0m| \x1b[0m", deco.ColumnSeparator(2, 0))
+	assert.Equal(t, "\x1b[38;2;200;100;0m+---+\x1b[0m", deco.RowSeparator(-1, []int{1}))
+}
@@ -27,6 +27,10 @@ Moreover, a few "preconfigured" column types are provided for common use cases:
 These 3 column types are similar to the "flex: none", "flex: N", and
 "flex: initial" CSS shorthand values, respectively.
 
+An [Equal] column shares the available width equally with the other Equal
+columns, so that they all have the exact same width regardless of their content.
+A [Percent] column takes a given percentage of the output width.
+
 A column can also be omitted from the output by using the special [Omit] column
 type.
 
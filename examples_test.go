@@ -92,7 +92,7 @@ func ExampleFlexed() {
 
 	writer.Flush()
 	// Output:
-	// | one sixth,  | one third,              | and half of the output width         |
+	// | one sixth,   | one third,              | and half of the output width        |
 }
 
 func ExampleWriter_SetDefaultColumn() {
@@ -145,6 +145,27 @@ func ExampleBoxDrawingTableDecorator() {
 	// └──────┴──────────┴───────┘
 }
 
+func ExampleTableDecorator_boundaries() {
+	deco := flexwriter.BoxDrawingTableDecorator().(*flexwriter.TableDecorator)
+	// use a dashed border before the last, auxiliary, column
+	deco.Boundaries = map[int]flexwriter.Boundary{
+		2: {Intersections: [3]string{"─┬─", "─┼─", "─┴─"}, VertBorder: " ┊ "},
+	}
+	writer := flexwriter.New()
+	writer.SetDecorator(deco)
+
+	writer.WriteRow("id", "name", "note")
+	writer.WriteRow("1", "alice", "admin")
+
+	writer.Flush()
+	// Output:
+	// ┌────┬───────┬───────┐
+	// │ id │ name  ┊ note  │
+	// ├────┼───────┼───────┤
+	// │ 1  │ alice ┊ admin │
+	// └────┴───────┴───────┘
+}
+
 func ExampleOmit() {
 	writer := flexwriter.New()
 	writer.SetColumns(
@@ -188,9 +209,27 @@ func ExampleShrinkable() {
 	// +--------------+----------+---------------+
 	// | columns stay | as small | as required   |
 	// +--------------+----------+---------------+
-	// +------+-------------+----------------------------+----------------------------+
-	// | but  | the row is  | and it can't all fit in a  | so the columns will shrink |
-	// | now  | much longer | single line of the output  | and contents will be       |
-	// |      |             |                            | wrapped                    |
-	// +------+-------------+----------------------------+----------------------------+
+	// +------+-------------+---------------------------+-----------------------------+
+	// | but  | the row is  | and it can't all fit in a | so the columns will shrink  |
+	// | now  | much longer | single line of the output | and contents will be        |
+	// |      |             |                           | wrapped                     |
+	// +------+-------------+---------------------------+-----------------------------+
+}
+
+func ExampleEqual() {
+	writer := flexwriter.New()
+	writer.SetColumns(flexwriter.Rigid{})
+	writer.SetDefaultColumn(flexwriter.Equal{Align: flexwriter.Center})
+	writer.SetDecorator(flexwriter.AsciiTableDecorator())
+	writer.SetWidth(60)
+
+	writer.WriteRow("week", "Mon", "Tue", "Wed", "Thu", "Fri")
+	writer.WriteRow(1, "", "holiday", "", "", "release")
+	writer.Flush()
+	// Output:
+	// +------+---------+---------+---------+---------+---------+
+	// | week |   Mon   |   Tue   |   Wed   |   Thu   |   Fri   |
+	// +------+---------+---------+---------+---------+---------+
+	// | 1    |         | holiday |         |         | release |
+	// +------+---------+---------+---------+---------+---------+
 }
@@ -0,0 +1,265 @@
+package flexwriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hchargois/flexwriter/flex"
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// flushAs flushes the rows with the given decorator, for an export format:
+// the escape sequences are removed, there is no caption nor row of the column
+// groups, and the lines are never clipped as it would make the output
+// invalid. If setup is not nil, it
+// is called once the rows are prepared, and may change the rows or the
+// configuration of the writer, which is restored afterwards.
+func (w *Writer) flushAs(deco Decorator, setup func()) (FlushStats, error) {
+	prevDeco, caption, overflow, strip := w.deco, w.caption, w.overflow, w.stripEscapes
+	rowFilter, tagFilter, rowLess := w.rowFilter, w.tagFilter, w.rowLess
+	width, maxWidth, fixedWidths, groups := w.width, w.maxWidth, w.fixedWidths, w.groups
+	defer func() {
+		w.deco, w.caption, w.overflow, w.stripEscapes = prevDeco, caption, overflow, strip
+		w.rowFilter, w.tagFilter, w.rowLess = rowFilter, tagFilter, rowLess
+		w.width, w.maxWidth, w.fixedWidths, w.groups = width, maxWidth, fixedWidths, groups
+	}()
+	w.deco, w.caption, w.overflow, w.stripEscapes = deco, Caption{}, OverflowWrap, true
+	w.groups = nil
+
+	w.prepareRows()
+	w.rows = dropGroupRows(w.rows)
+	// the rows are already filtered and sorted, which must not be done again
+	// on the rows added by setup
+	w.rowFilter, w.tagFilter, w.rowLess = nil, nil, nil
+	if setup != nil {
+		setup()
+	}
+	return w.flush()
+}
+
+// FlushRST is like [Writer.Flush], but writes the rows as a reStructuredText
+// grid table, e.g. for a Sphinx documentation, whatever the decorator; the
+// header row set with [Writer.SetHeaders] is separated from the body with a
+// "=" line. The columns are
+// sized as usual, so the content is wrapped in the cells as needed. The
+// escape sequences, e.g. colors, are removed, there is no caption, and the
+// lines are never clipped.
+func (w *Writer) FlushRST() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.flushAs(TableDecorator{
+		TopIntersections:    [3]string{"+-", "-+-", "-+"},
+		MiddleIntersections: [3]string{"+-", "-+-", "-+"},
+		BottomIntersections: [3]string{"+-", "-+-", "-+"},
+		VertBorders:         [3]string{"| ", " | ", " |"},
+		HorizBorders:        [3]string{"-", "-", "-"},
+		HeaderIntersections: [3]string{"+=", "=+=", "=+"},
+		HeaderBorder:        "=",
+	}, nil)
+	return err
+}
+
+// FlushOrg is like [Writer.Flush], but writes the rows as an Org-mode table,
+// e.g. to paste them in an Emacs Org document, whatever the decorator; the
+// header row set with [Writer.SetHeaders] is separated from the body with a
+// "|---+---|" line, as are the
+// rows around the separators written with [Writer.WriteSeparator]. As the
+// cells of Org tables can't span several lines, the content is never wrapped
+// and the target width is ignored. If some columns are not aligned on the
+// left, a first row holds the Org alignment cookies, e.g. "<r>". The "|" in
+// the cells are escaped as "\vert{}", and their lines are joined with
+// spaces. The escape sequences, e.g. colors, are removed, and there is no
+// caption.
+func (w *Writer) FlushOrg() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	deco := orgDecorator{}
+	_, err := w.flushAs(deco, func() {
+		// the cells must hold on a single line, without the "|" that would
+		// end them; the rows are copied so that they are kept as written if
+		// the output fails, see WriteError
+		w.rows = append([]row(nil), w.rows...)
+		for i, r := range w.rows {
+			if !r.rule {
+				w.rows[i].cells = transform(r.cells, orgEscaper.Replace)
+			}
+		}
+
+		var n int
+		for _, r := range w.rows {
+			if len(r.cells) > n {
+				n = len(r.cells)
+			}
+		}
+
+		cookies := make([]string, n)
+		var aligned bool
+		for ci := range cookies {
+			switch w.getColumnDef(ci).Alignment {
+			case Center:
+				cookies[ci], aligned = "<c>", true
+			case Right:
+				cookies[ci], aligned = "<r>", true
+			}
+		}
+		if aligned {
+			w.rows = append([]row{{cells: cookies, all: cookies}}, w.rows...)
+		}
+
+		// the columns are as wide as their content, so that nothing is wrapped
+		if w.hasTree() {
+			w.computeTreePrefixes()
+		}
+		w.fixedWidths = transform(w.flexItems(), func(it flex.Item) int {
+			if it.Size < 1 {
+				return 1
+			}
+			return it.Size
+		})
+		w.width, w.maxWidth = totalWidth(deco, w.fixedWidths), 0
+	})
+	return err
+}
+
+// dropGroupRows returns the rows without the row of the column groups, which
+// may still be there if a previous flush failed.
+func dropGroupRows(rows []row) []row {
+	var kept []row
+	for _, r := range rows {
+		if !r.group {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// orgEscaper escapes the content of the cells of Org-mode tables.
+var orgEscaper = strings.NewReplacer("|", `\vert{}`, "\r\n", " ", "\n", " ")
+
+// orgDecorator draws Org-mode tables.
+type orgDecorator struct{}
+
+func (d orgDecorator) RowSeparator(rowIdx int, widths []int) string {
+	return ""
+}
+
+func (d orgDecorator) ColumnSeparator(_, colIdx int) string {
+	switch colIdx {
+	case 0:
+		return "| "
+	case -1:
+		return " |"
+	default:
+		return " | "
+	}
+}
+
+func (d orgDecorator) RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string {
+	if above != HeaderRow || below == HeaderRow || below == NoRow {
+		return ""
+	}
+	return d.hline(widths)
+}
+
+func (d orgDecorator) RuleSeparator(_ int, _, _ RowKind, widths []int) string {
+	return d.hline(widths)
+}
+
+// hline returns an Org horizontal line, e.g. "|---+---|".
+func (d orgDecorator) hline(widths []int) string {
+	var sb strings.Builder
+	sb.WriteString("|-")
+	for i, w := range widths {
+		if i > 0 {
+			sb.WriteString("-+-")
+		}
+		sb.WriteString(strings.Repeat("-", w))
+	}
+	sb.WriteString("-|")
+	return sb.String()
+}
+
+func (d orgDecorator) ColumnSeparatorKind(rowIdx, colIdx int, _ RowKind) string {
+	return d.ColumnSeparator(rowIdx, colIdx)
+}
+
+// FlushJSON is like [Writer.Flush], but writes the rows as a JSON array, e.g.
+// to implement a JSON output format with the same code as the table output.
+// Each row is an array of the contents of its cells, as strings, or an object
+// if there is a header row set with [Writer.SetHeaders]: its cells are then
+// the keys of the cells of the other rows, in the order of the columns. The
+// omitted columns and the row of the column groups are left out, the escape
+// sequences are removed and the separators are ignored.
+func (w *Writer) FlushJSON() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	groups := w.groups
+	w.groups = nil
+	w.prepareRows()
+	w.groups = groups
+	rows := dropGroupRows(w.rows)
+	track := outputTracker{rows: rows}
+
+	var keys []string
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	var n int
+	for _, r := range rows {
+		if r.rule {
+			continue
+		}
+		cells := transform(r.cells, func(cell string) string {
+			return textutil.StripEscapes(cell)
+		})
+		if r.kind == HeaderRow {
+			if keys == nil {
+				keys = cells
+			}
+			continue
+		}
+
+		track.startRow(buf.Len())
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		n++
+		if keys == nil {
+			b, err := json.Marshal(cells)
+			if err != nil {
+				return fmt.Errorf("flexwriter: cannot encode a row to JSON: %w", err)
+			}
+			buf.Write(b)
+			continue
+		}
+		// the object is written by hand to keep the order of the columns
+		buf.WriteByte('{')
+		for i, cell := range cells {
+			key := fmt.Sprintf("column%d", i+1)
+			if i < len(keys) {
+				key = keys[i]
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			k, _ := json.Marshal(key)
+			v, _ := json.Marshal(cell)
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(v)
+		}
+		buf.WriteByte('}')
+	}
+	track.startRow(buf.Len())
+	buf.WriteString("]\n")
+
+	if err := track.write(w, buf.Bytes()); err != nil {
+		return err
+	}
+	w.rows = nil
+	return nil
+}
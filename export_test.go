@@ -0,0 +1,113 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlushRST(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(BoxDrawingTableDecorator())
+	writer.SetCaption(Caption{Text: "Fruits"})
+	writer.SetColumns(Rigid{}, Shrinkable{})
+	writer.SetWidth(20)
+
+	writer.WriteRow("apple", color.RedString("red or green"))
+	writer.WriteRow("banana", "yellow")
+	assert.NoError(t, writer.FlushRST())
+
+	assert.Equal(t, ""+
+		"+--------+---------+\n"+
+		"| apple  | red or  |\n"+
+		"|        | green   |\n"+
+		"+--------+---------+\n"+
+		"| banana | yellow  |\n"+
+		"+--------+---------+\n", buf.String())
+}
+
+func TestFlushOrg(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Flexed{Align: Right})
+	writer.SetWidth(10)
+
+	writer.WriteRow("apple", "red or green")
+	writer.WriteSeparator()
+	writer.WriteRow("banana", 12)
+	assert.NoError(t, writer.FlushOrg())
+
+	assert.Equal(t, ""+
+		"|        |          <r> |\n"+
+		"| apple  | red or green |\n"+
+		"|--------+--------------|\n"+
+		"| banana |           12 |\n", buf.String())
+
+	// the cells are escaped and on a single line
+	buf.Reset()
+	writer.WriteRow("a|b", "red\nor green")
+	assert.NoError(t, writer.FlushOrg())
+	assert.Equal(t, ""+
+		"|           |          <r> |\n"+
+		"| a\\vert{}b | red or green |\n", buf.String())
+}
+
+func TestFlushJSON(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Omit{}, Rigid{})
+
+	writer.WriteRow("apple", "hidden", color.RedString("red"))
+	writer.WriteSeparator()
+	writer.WriteRow("banana", "hidden", 12, "extra")
+	assert.NoError(t, writer.FlushJSON())
+	assert.Equal(t, `[["apple","red"],["banana","12","extra"]]`+"\n", buf.String())
+
+	// the header rows give the keys of the objects
+	buf.Reset()
+	writer.SetHeaders("fruit", "", "color")
+	writer.WriteRow("apple", "hidden", "red", "extra")
+	assert.NoError(t, writer.FlushJSON())
+	assert.Equal(t, `[{"fruit":"apple","color":"red","column3":"extra"}]`+"\n", buf.String())
+	assert.Equal(t, 0, writer.Stats().BufferedRows)
+
+	// the column groups are not keys
+	buf.Reset()
+	writer.SetColumnGroups(ColumnGroup{Header: "all", From: 0, To: 3})
+	writer.WriteRow("apple", "hidden", "red")
+	assert.NoError(t, writer.FlushJSON())
+	assert.Equal(t, `[{"fruit":"apple","color":"red"}]`+"\n", buf.String())
+
+	buf.Reset()
+	writer.SetHeaders()
+	writer.WriteRow("apple", "hidden", "red")
+	assert.NoError(t, writer.FlushJSON())
+	assert.Equal(t, `[["apple","red"]]`+"\n", buf.String())
+}
+
+func TestFlushOrgGroups(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetHeaderStyle(nil)
+	writer.SetHeaders("fruit", "color")
+	writer.SetColumnGroups(ColumnGroup{Header: "fruits", From: 0, To: 2})
+
+	writer.WriteRow("apple", "red")
+	assert.NoError(t, writer.FlushOrg())
+
+	assert.Equal(t, ""+
+		"| fruit | color |\n"+
+		"|-------+-------|\n"+
+		"| apple | red   |\n", buf.String())
+}
@@ -0,0 +1,74 @@
+package flexwriter
+
+// Fill defines how the output is stretched to the full target width when the
+// columns, once sized, are narrower than it. This interface is sealed, use one
+// of the provided implementations:
+//   - [FillColumn]
+//   - [FillGaps]
+type Fill interface {
+	fill(w *Writer, widths []int, extra int) (pads []int)
+}
+
+// FillColumn stretches the output by growing a single column.
+type FillColumn struct {
+	// Index is the index of the column to grow, as written with
+	// [Writer.WriteRow]. If it is negative, or if the column is omitted or
+	// doesn't exist, the last column is grown.
+	Index int
+}
+
+func (f FillColumn) fill(w *Writer, widths []int, extra int) []int {
+	idx := len(widths) - 1
+	if f.Index >= 0 && !w.isOmitted(f.Index) {
+		// the columns may be reordered or mirrored when flushing
+		for ci := range widths {
+			if w.getColumnDef(ci).written == f.Index {
+				idx = ci
+			}
+		}
+	}
+	widths[idx] += extra
+	return make([]int, len(widths))
+}
+
+// FillGaps stretches the output by widening the gaps between the columns, as
+// evenly as possible. The columns keep their width, so that the content is
+// aligned as if there were no stretching. If there is a single column, the
+// output is not stretched.
+type FillGaps struct{}
+
+func (FillGaps) fill(w *Writer, widths []int, extra int) []int {
+	pads := make([]int, len(widths))
+	gaps := len(widths) - 1
+	for i := 0; i < gaps; i++ {
+		pads[i] = extra / gaps
+		if i < extra%gaps {
+			pads[i]++
+		}
+	}
+	return pads
+}
+
+// SetFill sets how the output is stretched to the full target width when the
+// columns are narrower than it, e.g. so that the right border of a table is
+// aligned with other full-width elements. If fill is nil, which is the default,
+// the output is not stretched.
+func (w *Writer) SetFill(fill Fill) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.fill = fill
+}
+
+// stretch applies the fill setting, growing either the widths or the pads of
+// the layout.
+func (w *Writer) stretch(l *layout) {
+	extra := w.targetWidth() - totalWidth(l.deco, l.outerWidths())
+	if w.fill == nil || extra <= 0 || len(l.widths) == 0 {
+		return
+	}
+	pads := w.fill.fill(w, l.widths, extra)
+	for i := range pads {
+		l.pads[i] += pads[i]
+	}
+}
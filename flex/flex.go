@@ -9,11 +9,22 @@
 //   - no infinite container size (container size is the width of the terminal
 //     or a fallback size, and in both cases non-zero)
 //   - no wrapping (in the sense of flex-wrap) of the items on multiple lines
-//   - items have no padding so inner width is the same as outer width (any
-//     padding between items is subtracted from the container size prior to
-//     running the algorithm)
+//   - items have no padding so inner width is the same as outer width; the
+//     space around and between the items is that of the [Container], i.e.
+//     its padding and its gaps, which [ResolveContainer] leaves out of the
+//     space shared between the items
 package flex
 
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrNoSolution is returned by [Resolve] if the algorithm fails to converge.
+// This should not happen with valid items.
+var ErrNoSolution = errors.New("flex: no solution found")
+
 type Item struct {
 	Basis  int // -1 = auto
 	Grow   int
@@ -26,6 +37,9 @@ type Item struct {
 	Min int
 	// maximum size for the item; if <=0, no maximum is enforced
 	Max int
+	// position of the item relative to the others, like the CSS order
+	// property; it doesn't change the sizes, see [Ordered]
+	Order int
 
 	flexBaseSize   int
 	hypoMainSize   int
@@ -94,7 +108,108 @@ func (it *Item) sizeIfInflexible(useGrow bool) {
 	}
 }
 
+// Ordered returns the indexes of the items in the order they are laid out: by
+// increasing Order, and in their original order for equal Orders.
+func Ordered(items []Item) []int {
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return items[indexes[a]].Order < items[indexes[b]].Order
+	})
+	return indexes
+}
+
+// TieBreak chooses which items get the spare cells left over when the free
+// space can't be divided exactly between the items. Each item that grows or
+// shrinks first gets the integer part of its exact share of the free space;
+// the spare cells, fewer than those items, are then given one by one to the
+// items chosen by the tie break, at most one cell each. When the items
+// shrink, a spare cell is a cell that an item is shrunk less. The choice only
+// depends on the items, so that it is the same for the same items.
+type TieBreak int
+
+const (
+	// TieBreakDefault gives the spare cells to the items whose exact share has
+	// the largest fractional part (largest remainder method), the first ones
+	// in case of equality; items of equal weights thus get sizes that differ
+	// by at most 1, the larger ones first.
+	TieBreakDefault TieBreak = iota
+	// TieBreakFirst gives the spare cells to the first items that grow or
+	// shrink, whatever the fractional parts of their shares: e.g. 10 cells
+	// shared between 3 items of equal weights give them 4, 3 and 3 cells.
+	// Only the spare cells are given by position, not the shares themselves.
+	TieBreakFirst
+	// TieBreakLast gives the spare cells to the last items that grow or
+	// shrink, whatever the fractional parts of their shares: e.g. 3, 3 and 4
+	// cells for 10 cells shared between 3 items of equal weights.
+	TieBreakLast
+	// TieBreakWidest gives the spare cells to the items that are the widest
+	// once they got the integer part of their shares, the first ones in case
+	// of equality.
+	TieBreakWidest
+	// TieBreakProportional is another name of TieBreakDefault, for the
+	// largest remainder method.
+	TieBreakProportional = TieBreakDefault
+)
+
+// ResolveFlexLengths is like [Resolve], but panics if no solution is found.
 func ResolveFlexLengths(items []Item, containerSize int) []int {
+	lens, err := Resolve(items, containerSize)
+	if err != nil {
+		panic(err)
+	}
+	return lens
+}
+
+// Resolve computes the sizes of the items within a container of the given
+// size. It returns [ErrNoSolution] if the algorithm fails to converge.
+func Resolve(items []Item, containerSize int) ([]int, error) {
+	return ResolveTieBreak(items, containerSize, TieBreakDefault)
+}
+
+// Container is the space the items are laid out in, made of the items, of the
+// gaps between them and of its padding.
+type Container struct {
+	// Size is the total size of the container.
+	Size int
+	// Gap is the size of the gaps between two adjacent items.
+	Gap int
+	// Gaps, if not nil, sets the size of each gap instead of Gap: Gaps[i] is
+	// the size of the gap between the items i and i+1.
+	Gaps []int
+	// PaddingLeft is the size of the space before the first item.
+	PaddingLeft int
+	// PaddingRight is the size of the space after the last item.
+	PaddingRight int
+}
+
+// ItemsSize returns the size left for n items in the container, i.e. its size
+// without its padding and the gaps between the items.
+func (c Container) ItemsSize(n int) int {
+	size := c.Size - c.PaddingLeft - c.PaddingRight
+	for i := 0; i < n-1; i++ {
+		if c.Gaps != nil {
+			if i < len(c.Gaps) {
+				size -= c.Gaps[i]
+			}
+		} else {
+			size -= c.Gap
+		}
+	}
+	return size
+}
+
+// ResolveContainer is like [ResolveTieBreak], but the items are laid out in
+// the given container; the sizes returned are those of the items only.
+func ResolveContainer(items []Item, container Container, tieBreak TieBreak) ([]int, error) {
+	return ResolveTieBreak(items, container.ItemsSize(len(items)), tieBreak)
+}
+
+// ResolveTieBreak is like [Resolve], but the spare cells left over by the
+// rounding of the shares of the free space go to the items chosen by tieBreak.
+func ResolveTieBreak(items []Item, containerSize int, tieBreak TieBreak) ([]int, error) {
 	var mutItems []*Item
 	for i := range items {
 		mutItems = append(mutItems, &items[i])
@@ -121,7 +236,7 @@ func ResolveFlexLengths(items []Item, containerSize int) []int {
 		for i, it := range mutItems {
 			lens[i] = it.hypoMainSize
 		}
-		return lens
+		return lens, nil
 	}
 
 	// still spec 9.7 / 1.
@@ -148,7 +263,7 @@ func ResolveFlexLengths(items []Item, containerSize int) []int {
 		iterations++
 		if iterations > len(mutItems)+1 {
 			// avoid infinite looping at all cost, but shouldn't happen
-			panic("no solution found")
+			return nil, ErrNoSolution
 		}
 
 		// spec 9.7 / 4.a
@@ -168,40 +283,23 @@ func ResolveFlexLengths(items []Item, containerSize int) []int {
 
 		// spec 9.7 / 4.c
 		if remainingFreeSpace != 0 {
-			if useGrow {
-				sumGF := sumUnfrozenGrowFactors(mutItems)
-				for _, it := range mutItems {
-					if it.frozen {
-						continue
-					}
-
-					growSpace := remainingFreeSpace * it.Grow / sumGF
-					it.targetMainSize = it.flexBaseSize + growSpace
-					remainingFreeSpace -= growSpace
-					sumGF -= it.Grow
+			weights := make([]int, len(mutItems))
+			for i, it := range mutItems {
+				if it.frozen {
+					continue
 				}
-			} else {
-				scaledShrinks := make([]int, len(mutItems))
-				var sumScaledShrinks int
-				for i, it := range mutItems {
-					if it.frozen {
-						continue
-					}
-					scaledShrinks[i] = it.Shrink * it.flexBaseSize
-					sumScaledShrinks += scaledShrinks[i]
+				if useGrow {
+					weights[i] = it.Grow
+				} else {
+					weights[i] = it.Shrink * it.flexBaseSize
 				}
-
-				for i, it := range mutItems {
-					if it.frozen {
-						continue
-					}
-
-					shrinkSpace := remainingFreeSpace * scaledShrinks[i] / sumScaledShrinks
-					// remember shrinkSpace is negative so when the spec says
-					// remove the absolute value, we instead add the value
-					it.targetMainSize = it.flexBaseSize + shrinkSpace
-					remainingFreeSpace -= shrinkSpace
-					sumScaledShrinks -= scaledShrinks[i]
+			}
+			// when shrinking, the space is negative so when the spec says
+			// remove the absolute value, we instead add the value
+			shares := distribute(mutItems, weights, remainingFreeSpace, tieBreak)
+			for i, it := range mutItems {
+				if !it.frozen {
+					it.targetMainSize = it.flexBaseSize + shares[i]
 				}
 			}
 		}
@@ -251,7 +349,7 @@ func ResolveFlexLengths(items []Item, containerSize int) []int {
 	for i, it := range mutItems {
 		lens[i] = it.targetMainSize
 	}
-	return lens
+	return lens, nil
 }
 
 func allFrozen(items []*Item) bool {
@@ -263,12 +361,56 @@ func allFrozen(items []*Item) bool {
 	return true
 }
 
-func sumUnfrozenGrowFactors(items []*Item) int {
+// distribute splits space, which is negative when shrinking, between the
+// unfrozen items in proportion to their weights, and returns the share of each
+// item.
+func distribute(items []*Item, weights []int, space int, tieBreak TieBreak) []int {
+	shares := make([]int, len(items))
 	var sum int
-	for _, it := range items {
+	for i, it := range items {
 		if !it.frozen {
-			sum += it.Grow
+			sum += weights[i]
 		}
 	}
-	return sum
+	if sum == 0 {
+		return shares
+	}
+
+	// each item gets the integer part of its exact share, computed in floating
+	// point so that it doesn't depend on the order of the items, then the
+	// spare cells are given one by one to the candidates, in the order of the
+	// tie break; there are fewer spare cells than candidates
+	var candidates []int
+	remainders := make([]float64, len(items))
+	spare := space
+	for i, it := range items {
+		if it.frozen || weights[i] == 0 {
+			continue
+		}
+		exact := float64(space) * float64(weights[i]) / float64(sum)
+		shares[i] = int(math.Floor(exact))
+		remainders[i] = exact - float64(shares[i])
+		spare -= shares[i]
+		candidates = append(candidates, i)
+	}
+	switch tieBreak {
+	case TieBreakLast:
+		for a, b := 0, len(candidates)-1; a < b; a, b = a+1, b-1 {
+			candidates[a], candidates[b] = candidates[b], candidates[a]
+		}
+	case TieBreakWidest:
+		sort.SliceStable(candidates, func(a, b int) bool {
+			ia, ib := candidates[a], candidates[b]
+			return items[ia].flexBaseSize+shares[ia] > items[ib].flexBaseSize+shares[ib]
+		})
+	case TieBreakDefault:
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return remainders[candidates[a]] > remainders[candidates[b]]
+		})
+	}
+	for k := 0; spare > 0; k++ {
+		shares[candidates[k%len(candidates)]]++
+		spare--
+	}
+	return shares
 }
@@ -93,21 +93,21 @@ func TestFlexGrowBasisNonZero(t *testing.T) {
 				{Grow: 1, Basis: 10},
 				{Grow: 2},
 			},
-			exp: []int{26, 34},
+			exp: []int{27, 33},
 		},
 		{
 			items: []Item{
 				{Grow: 1},
 				{Grow: 2, Basis: 10},
 			},
-			exp: []int{16, 44},
+			exp: []int{17, 43},
 		},
 		{
 			items: []Item{
 				{Grow: 1},
 				{Grow: 2, Size: 10, Basis: -1},
 			},
-			exp: []int{16, 44},
+			exp: []int{17, 43},
 		},
 		{
 			items: []Item{
@@ -291,7 +291,7 @@ func TestFlexMixed2(t *testing.T) {
 				{Grow: 1, Shrink: 1, Basis: 0, Size: 3, Min: 3},
 				{Grow: 1, Shrink: 1, Basis: 0, Size: 3, Min: 3},
 			},
-			exp: []int{20, 5, 23, 6, 6},
+			exp: []int{20, 6, 23, 6, 5},
 		},
 	} {
 		lens := ResolveFlexLengths(tc.items, 60)
@@ -351,6 +351,60 @@ func FuzzResolveFlexLengths2Items(f *testing.F) {
 	})
 }
 
+// tie-break test cases: the free space of bases and shrinks is not divisible
+// by 3, nor is 10 once shared between the weights of 3, 2 and 1
+var (
+	tieBases   = []Item{{Basis: 2, Grow: 1}, {Basis: 5, Grow: 1}, {Basis: 3, Grow: 1}}
+	tieShrinks = []Item{{Basis: 2, Shrink: 1}, {Basis: 5, Shrink: 1}, {Basis: 3, Shrink: 1}}
+	tieWeights = []Item{{Basis: 0, Grow: 3}, {Basis: 0, Grow: 2}, {Basis: 0, Grow: 1}}
+	tieEqual   = []Item{{Grow: 1}, {Grow: 1}, {Grow: 1}}
+)
+
+func assertTieBreak(t *testing.T, items []Item, size int, tieBreak TieBreak, exp []int) {
+	t.Helper()
+	lens, err := ResolveTieBreak(append([]Item(nil), items...), size, tieBreak)
+	assert.NoError(t, err)
+	assert.Equal(t, exp, lens)
+}
+
+func TestTieBreakDefault(t *testing.T) {
+	assertTieBreak(t, tieBases, 11, TieBreakDefault, []int{3, 5, 3})
+	assertTieBreak(t, tieShrinks, 8, TieBreakDefault, []int{2, 4, 2})
+	// 5, 3.33 and 1.67: the largest fractional part is the last one
+	assertTieBreak(t, tieWeights, 10, TieBreakDefault, []int{5, 3, 2})
+	assertTieBreak(t, tieWeights, 11, TieBreakDefault, []int{5, 4, 2})
+	assertTieBreak(t, tieEqual, 10, TieBreakDefault, []int{4, 3, 3})
+	assertTieBreak(t, tieEqual, 11, TieBreakDefault, []int{4, 4, 3})
+}
+
+func TestTieBreakFirst(t *testing.T) {
+	assertTieBreak(t, tieBases, 11, TieBreakFirst, []int{3, 5, 3})
+	assertTieBreak(t, tieShrinks, 8, TieBreakFirst, []int{2, 4, 2})
+	// the spare cell goes to the first item, whatever the fractional parts
+	assertTieBreak(t, tieWeights, 10, TieBreakFirst, []int{6, 3, 1})
+	assertTieBreak(t, tieEqual, 11, TieBreakFirst, []int{4, 4, 3})
+}
+
+func TestTieBreakLast(t *testing.T) {
+	assertTieBreak(t, tieBases, 11, TieBreakLast, []int{2, 5, 4})
+	assertTieBreak(t, tieShrinks, 8, TieBreakLast, []int{1, 4, 3})
+	assertTieBreak(t, tieWeights, 10, TieBreakLast, []int{5, 3, 2})
+	assertTieBreak(t, tieEqual, 10, TieBreakLast, []int{3, 3, 4})
+}
+
+func TestTieBreakWidest(t *testing.T) {
+	assertTieBreak(t, tieBases, 11, TieBreakWidest, []int{2, 6, 3})
+	assertTieBreak(t, tieShrinks, 8, TieBreakWidest, []int{1, 5, 2})
+	assertTieBreak(t, tieWeights, 10, TieBreakWidest, []int{6, 3, 1})
+	assertTieBreak(t, tieEqual, 10, TieBreakWidest, []int{4, 3, 3})
+}
+
+func TestTieBreakProportional(t *testing.T) {
+	assertTieBreak(t, tieWeights, 10, TieBreakProportional, []int{5, 3, 2})
+	assertTieBreak(t, tieWeights, 11, TieBreakProportional, []int{5, 4, 2})
+	assertTieBreak(t, tieEqual, 11, TieBreakProportional, []int{4, 4, 3})
+}
+
 func FuzzResolveFlexLengths3Items(f *testing.F) {
 	f.Add(uint16(100),
 		1, 1, 1, 100, 1, 1000,
@@ -387,3 +441,24 @@ func FuzzResolveFlexLengths3Items(f *testing.F) {
 		}
 	})
 }
+
+func TestOrdered(t *testing.T) {
+	items := []Item{{Order: 1}, {}, {Order: -1}, {Order: 1}, {}}
+	assert.Equal(t, []int{2, 1, 4, 0, 3}, Ordered(items))
+}
+
+func TestResolveContainer(t *testing.T) {
+	items := []Item{{Grow: 1}, {Grow: 1}, {Grow: 1}}
+	container := Container{Size: 20, Gap: 3, PaddingLeft: 2, PaddingRight: 3}
+	assert.Equal(t, 9, container.ItemsSize(3))
+	lens, err := ResolveContainer(items, container, TieBreakDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 3, 3}, lens)
+
+	// the items are modified by the resolution
+	items = []Item{{Grow: 1}, {Grow: 1}, {Grow: 1}}
+	container.Gaps = []int{1, 0}
+	lens, err = ResolveContainer(items, container, TieBreakDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5, 5, 4}, lens)
+}
@@ -2,15 +2,22 @@ package flexwriter
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
 	text "github.com/MichaelMure/go-term-text"
+	"github.com/fatih/color"
 	"github.com/hchargois/flexwriter/flex"
+	"github.com/hchargois/flexwriter/textutil"
 	"golang.org/x/term"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Column holds the configuration of a column for the flex writer. This
@@ -19,7 +26,23 @@ import (
 //   - [Rigid]
 //   - [Flexed]
 //   - [Shrinkable]
+//   - [Equal]
 //   - [Omit]
+//
+// Most of them have these fields in common:
+//   - Style, if not nil, is the color of the content of the cells of the
+//     column, e.g. to dim a column of timestamps, unless a cell has its own
+//     style (see [RowBuilder.Style]) or spans several columns.
+//   - Order is the position of the column in the output relative to the
+//     other columns, like the CSS order property: the columns are laid out by
+//     increasing Order, in the order of the cells for equal Orders; default
+//     is 0.
+//   - Priority is how important the column is when the output is too narrow
+//     for all the columns: if any column has a non-zero Priority, the columns
+//     are then dropped by increasing Priority, the right-most first, until
+//     the others fit; default is 0. The frozen columns are never dropped (see
+//     [Writer.SetFrozenColumns]), and the dropped ones are counted by the
+//     overflow indicator (see [Writer.SetOverflowIndicator]).
 type Column interface {
 	flex() flexItem
 }
@@ -27,6 +50,53 @@ type Column interface {
 type flexItem struct {
 	flex.Item
 	Alignment
+	equal      bool              // whether the column is an Equal column
+	breakAfter func(r rune) bool // extra break opportunities of the column
+	percent    int               // if > 0, the basis is this % of the free space
+	tree       bool              // whether the column is a Tree column
+	mask       string            // if not empty, replaces the content
+	frozen     bool              // whether the column is frozen, when flushing
+	written    int               // index of the column as written, when flushing
+	link       string            // template of the URL of the cells
+	priority   int               // the lowest are dropped first if the output is too narrow
+	style      style             // style of the content of the cells
+	wrap       WrapMode
+}
+
+// Alignment is the alignment of the content within a column.
+type Alignment = textutil.Alignment
+
+const (
+	Left   = textutil.Left
+	Center = textutil.Center
+	Right  = textutil.Right
+)
+
+// WrapMode is how the content of a column is wrapped when it is wider than
+// the column.
+type WrapMode int
+
+const (
+	// WrapWord wraps the content between words, and only breaks the words
+	// that are wider than the column.
+	WrapWord WrapMode = iota
+	// WrapChar wraps the content anywhere, filling the lines entirely, e.g.
+	// for hashes or URLs; the column can then be as narrow as a single cell,
+	// unless its Min is set.
+	WrapChar
+	// WrapNone never wraps the content: the column is as wide as its widest
+	// cell, unless its Max or its fixed width is smaller, in which case the
+	// content is cut, and ends with the clip marker (see
+	// [Writer.SetClipMarker]).
+	WrapNone
+)
+
+// Width returns the display width of s, i.e. the number of terminal cells it
+// takes, measured the exact same way the writer measures the content of the
+// cells: escape sequences don't take any space, and wide characters (e.g. CJK
+// characters) take two cells.
+func Width(s string) int {
+	return textutil.Width(s)
 }
 
 // Rigid columns try to match the size of their content, as long
@@ -46,6 +116,23 @@ type Rigid struct {
 	Max int
 	// Align is the alignment of the content within the column; default is left.
 	Align Alignment
+	// Break, if not nil, allows the lines to also be broken after any
+	// character for which it returns true, e.g. after a "/" in paths.
+	Break func(r rune) bool
+	// LinkTemplate, if not empty, makes the cells hyperlinks to the URL
+	// obtained by replacing "{cell}" in the template with the escaped content
+	// of the cell, e.g. "https://tracker/issue/{cell}". The links are OSC 8
+	// escape sequences, that don't change the layout.
+	LinkTemplate string
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
 }
 
 func (r Rigid) flex() flexItem {
@@ -57,8 +144,14 @@ func (r Rigid) flex() flexItem {
 			Basis: Auto,
 			Min:   r.Min,
 			Max:   r.Max,
+			Order: r.Order,
 		},
-		Alignment: r.Align,
+		Alignment:  r.Align,
+		breakAfter: r.Break,
+		link:       r.LinkTemplate,
+		priority:   r.Priority,
+		style:      newStyle(r.Style),
+		wrap:       r.Wrap,
 	}
 }
 
@@ -69,8 +162,9 @@ func (r Rigid) flex() flexItem {
 // Grow of 0 and a Shrinkable of the given Weight, or 1 if 0/unset. This is similar
 // to a "flex: initial" in CSS.
 type Shrinkable struct {
-	// Weight is the shrink weight of the column; if 0 or less, it defaults to 1.
-	Weight int
+	// Weight is the shrink weight of the column, which can be fractional, e.g.
+	// 1.5; if 0 or less, it defaults to 1.
+	Weight float64
 	// Min is the minimum width of the column. If the content is smaller, the
 	// column will be padded.
 	Min int
@@ -79,23 +173,56 @@ type Shrinkable struct {
 	Max int
 	// Align is the alignment of the content within the column; default is left.
 	Align Alignment
+	// Break, if not nil, allows the lines to also be broken after any
+	// character for which it returns true, e.g. after a "/" in paths.
+	Break func(r rune) bool
+	// LinkTemplate, if not empty, makes the cells hyperlinks to the URL
+	// obtained by replacing "{cell}" in the template with the escaped content
+	// of the cell, e.g. "https://tracker/issue/{cell}". The links are OSC 8
+	// escape sequences, that don't change the layout.
+	LinkTemplate string
+	// BreakWords allows the column to be narrower than its longest word, which
+	// is then broken anywhere; by default, the minimum width of the column is
+	// the width of its longest word, unless Min is set.
+	//
+	// Deprecated: BreakWords is the same as a Min of 1; set Wrap to [WrapChar]
+	// instead to also fill the lines entirely.
+	BreakWords bool
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
 }
 
 func (s Shrinkable) flex() flexItem {
+	if s.BreakWords && s.Min < 1 {
+		s.Min = 1
+	}
 	if s.Max != 0 && s.Min > s.Max {
 		s.Min = s.Max
 	}
-	if s.Weight < 1 {
+	if s.Weight <= 0 {
 		s.Weight = 1
 	}
 	return flexItem{
 		Item: flex.Item{
 			Basis:  Auto,
-			Shrink: s.Weight,
+			Shrink: scaleWeight(s.Weight),
 			Min:    s.Min,
 			Max:    s.Max,
+			Order:  s.Order,
 		},
-		Alignment: s.Align,
+		Alignment:  s.Align,
+		breakAfter: s.Break,
+		link:       s.LinkTemplate,
+		priority:   s.Priority,
+		style:      newStyle(s.Style),
+		wrap:       s.Wrap,
 	}
 }
 
@@ -109,6 +236,23 @@ func (o Omit) flex() flexItem {
 	panic("Omit.flexed() should not be called")
 }
 
+// weightScale is the factor applied to the weights of the columns, which can be
+// fractional, to pass them to the flex package, which only deals with integers.
+const weightScale = 1000
+
+// scaleWeight converts a weight to an integer flex weight; a positive weight
+// never becomes 0.
+func scaleWeight(weight float64) int {
+	if weight <= 0 {
+		return 0
+	}
+	scaled := int(math.Round(weight * weightScale))
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
+
 // Auto can be set as the Basis of a [Flexbox] column to make the basis as large
 // as the content.
 const Auto = -1
@@ -119,8 +263,9 @@ const Auto = -1
 //
 // A Flexed column is similar to a "flex: N" column in CSS.
 type Flexed struct {
-	// Weight is the grow weight of the column; if 0 or less, it defaults to 1.
-	Weight int
+	// Weight is the grow weight of the column, which can be fractional, e.g.
+	// 1.5; if 0 or less, it defaults to 1.
+	Weight float64
 	// Min is the minimum width of the column. If 0, it defaults to the
 	// "min content" size, i.e. the size of the longest word in the content.
 	Min int
@@ -129,24 +274,185 @@ type Flexed struct {
 	Max int
 	// Align is the alignment of the content within the column; default is left.
 	Align Alignment
+	// Break, if not nil, allows the lines to also be broken after any
+	// character for which it returns true, e.g. after a "/" in paths.
+	Break func(r rune) bool
+	// LinkTemplate, if not empty, makes the cells hyperlinks to the URL
+	// obtained by replacing "{cell}" in the template with the escaped content
+	// of the cell, e.g. "https://tracker/issue/{cell}". The links are OSC 8
+	// escape sequences, that don't change the layout.
+	LinkTemplate string
+	// BreakWords allows the column to be narrower than its longest word, which
+	// is then broken anywhere; by default, the minimum width of the column is
+	// the width of its longest word, unless Min is set.
+	//
+	// Deprecated: BreakWords is the same as a Min of 1; set Wrap to [WrapChar]
+	// instead to also fill the lines entirely.
+	BreakWords bool
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
 }
 
 func (f Flexed) flex() flexItem {
-	if f.Weight < 1 {
+	if f.Weight <= 0 {
 		f.Weight = 1
 	}
+	if f.BreakWords && f.Min < 1 {
+		f.Min = 1
+	}
 	if f.Max != 0 && f.Min > f.Max {
 		f.Min = f.Max
 	}
 	return flexItem{
 		Item: flex.Item{
-			Grow:   f.Weight,
-			Shrink: 1,
+			Grow:   scaleWeight(f.Weight),
+			Shrink: weightScale,
 			Basis:  0,
 			Min:    f.Min,
 			Max:    f.Max,
+			Order:  f.Order,
 		},
-		Alignment: f.Align,
+		Alignment:  f.Align,
+		breakAfter: f.Break,
+		link:       f.LinkTemplate,
+		priority:   f.Priority,
+		style:      newStyle(f.Style),
+		wrap:       f.Wrap,
+	}
+}
+
+// Equal columns all have the exact same width, regardless of their content:
+// the width left by the other columns is shared equally between them, and
+// longer content is wrapped.
+//
+// This is similar to a "flex: 1 1 0" in CSS, except that the min width does not
+// default to the "min content" size, and that any remainder of the division of
+// the available width is left unused so that all widths are identical.
+type Equal struct {
+	// Max is the maximum width of the column. As all Equal columns have the
+	// same width, the smallest Max of all Equal columns applies to all of
+	// them. If Max is 0, then there is no maximum width.
+	Max int
+	// Align is the alignment of the content within the column; default is left.
+	Align Alignment
+	// Break, if not nil, allows the lines to also be broken after any
+	// character for which it returns true, e.g. after a "/" in paths.
+	Break func(r rune) bool
+	// LinkTemplate, if not empty, makes the cells hyperlinks to the URL
+	// obtained by replacing "{cell}" in the template with the escaped content
+	// of the cell, e.g. "https://tracker/issue/{cell}". The links are OSC 8
+	// escape sequences, that don't change the layout.
+	LinkTemplate string
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
+}
+
+func (e Equal) flex() flexItem {
+	return flexItem{
+		Item: flex.Item{
+			Grow:   weightScale,
+			Shrink: weightScale,
+			Basis:  0,
+			Min:    1,
+			Max:    e.Max,
+			Order:  e.Order,
+		},
+		Alignment:  e.Align,
+		breakAfter: e.Break,
+		link:       e.LinkTemplate,
+		equal:      true,
+		priority:   e.Priority,
+		style:      newStyle(e.Style),
+		wrap:       e.Wrap,
+	}
+}
+
+// Percent columns take N percent of the output width, not counting the width of
+// the column separators, regardless of the size of their content; but they are
+// never narrower than their longest word. They can shrink like a [Shrinkable]
+// if the output is too narrow.
+type Percent struct {
+	// N is the percentage of the output width the column takes.
+	N int
+	// Align is the alignment of the content within the column; default is left.
+	Align Alignment
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
+}
+
+func (p Percent) flex() flexItem {
+	if p.N < 0 {
+		p.N = 0
+	}
+	return flexItem{
+		Item: flex.Item{
+			Shrink: weightScale,
+			Order:  p.Order,
+		},
+		Alignment: p.Align,
+		percent:   p.N,
+		priority:  p.Priority,
+		style:     newStyle(p.Style),
+		wrap:      p.Wrap,
+	}
+}
+
+// Masked columns hide their content, e.g. passwords, by replacing it with a
+// mask of a fixed width, so that neither the values nor their lengths are
+// disclosed; empty cells are left empty. The mask replaces the content when
+// the rows are written, so the original values are never stored. Other than
+// that, a Masked column is like a [Rigid].
+type Masked struct {
+	// Mask replaces the content of the cells; default is "••••••".
+	Mask string
+	// Align is the alignment of the content within the column; default is left.
+	Align Alignment
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
+}
+
+func (m Masked) flex() flexItem {
+	if m.Mask == "" {
+		m.Mask = "••••••"
+	}
+	return flexItem{
+		Item: flex.Item{
+			Basis: Auto,
+			Order: m.Order,
+		},
+		Alignment: m.Align,
+		mask:      m.Mask,
+		priority:  m.Priority,
+		style:     newStyle(m.Style),
+		wrap:      m.Wrap,
 	}
 }
 
@@ -158,10 +464,14 @@ type Flexbox struct {
 	// it grows or shrinks. Use the constant Auto (or -1) to make the basis
 	// equal to the content size.
 	Basis int
-	// Grow is the flexbox grow weight.
-	Grow int
-	// Shrink is the flexbox shrink weight.
-	Shrink int
+	// BasisPercent, if > 0, makes the basis this percentage of the output
+	// width, not counting the width of the column separators, as for a
+	// [Percent] column; it overrides Basis.
+	BasisPercent int
+	// Grow is the flexbox grow weight; it can be fractional.
+	Grow float64
+	// Shrink is the flexbox shrink weight; it can be fractional.
+	Shrink float64
 	// Min is the minimum width of the column. If 0, it defaults to the
 	// "min content" size, i.e. the size of the longest word in the content.
 	Min int
@@ -170,36 +480,170 @@ type Flexbox struct {
 	Max int
 	// Align is the alignment of the content within the column; default is left.
 	Align Alignment
+	// Break, if not nil, allows the lines to also be broken after any
+	// character for which it returns true, e.g. after a "/" in paths.
+	Break func(r rune) bool
+	// LinkTemplate, if not empty, makes the cells hyperlinks to the URL
+	// obtained by replacing "{cell}" in the template with the escaped content
+	// of the cell, e.g. "https://tracker/issue/{cell}". The links are OSC 8
+	// escape sequences, that don't change the layout.
+	LinkTemplate string
+	// BreakWords allows the column to be narrower than its longest word, which
+	// is then broken anywhere; by default, the minimum width of the column is
+	// the width of its longest word, unless Min is set.
+	//
+	// Deprecated: BreakWords is the same as a Min of 1; set Wrap to [WrapChar]
+	// instead to also fill the lines entirely.
+	BreakWords bool
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
 }
 
 func (f Flexbox) flex() flexItem {
+	if f.BreakWords && f.Min < 1 {
+		f.Min = 1
+	}
 	if f.Max != 0 && f.Min > f.Max {
 		f.Min = f.Max
 	}
 	return flexItem{
 		Item: flex.Item{
 			Basis:  f.Basis,
-			Grow:   f.Grow,
-			Shrink: f.Shrink,
+			Grow:   scaleWeight(f.Grow),
+			Shrink: scaleWeight(f.Shrink),
 			Min:    f.Min,
 			Max:    f.Max,
+			Order:  f.Order,
 		},
-		Alignment: f.Align,
+		Alignment:  f.Align,
+		breakAfter: f.Break,
+		link:       f.LinkTemplate,
+		percent:    f.BasisPercent,
+		priority:   f.Priority,
+		style:      newStyle(f.Style),
+		wrap:       f.Wrap,
 	}
 }
 
 type Writer struct {
-	width       int
-	output      io.Writer
-	omittedCols []bool     // whether each configured column is omitted
-	omitDefault bool       // whether unconfigured columns are omitted
-	columns     []flexItem // only non-omitted columns
-	defaultCol  flexItem
-	deco        Decorator
+	width        int
+	maxWidth     int // if > 0, caps the target width
+	output       io.Writer
+	colDefs      []Column   // configured columns, nil if left to the default
+	omittedCols  []bool     // whether each configured column is omitted
+	omitDefault  bool       // whether unconfigured columns are omitted
+	columns      []flexItem // only non-omitted columns
+	defaultDef   Column
+	defaultCol   flexItem
+	deco         Decorator
+	fill         Fill
+	gap          Gap
+	collapseGaps bool // whether gaps may collapse before columns shrink
+	tieBreak     WidthTieBreak
+	hyphenation  int    // minimum length of hyphenated fragments, 0 if disabled
+	contMarker   string // prefix of the wrapped lines of the cells
+	rowIndent    int    // width of each level of WriteRowIndent, if > 0
+	fixedWidths  []int  // widths of the columns set with SetFixedWidths
+	lastWidths   []int  // widths of the columns at the last flush
+	plainDelim   string // delimiter of the cells in plain mode, if enabled
+	redactor     func(colIdx int, value string) string
+	formatters   map[int]Formatter // formatters of the columns, by written index
+	nilText      *string           // placeholder of the nil cells, if set
+	errStyle     style             // style of the cells that are errors
+	lazy         bool              // whether the cells are converted when flushing
+	mirrored     bool              // whether the columns are mirrored, see SetMirror
+	colOrder     []int             // visible columns in the order of their Order, when flushing
+	frozen       []int             // frozen columns, by written index
+	pageSize     int               // number of rows of the pages, 0 for one page
+	pageFooter   string            // format of the footer of the pages
+	bands        bool              // whether the columns are split in bands
+	bandKeys     []int             // key columns of the bands, by written index
+	inBand       bool              // whether the rows are being flushed in bands
+	flushCtx     context.Context   // context of FlushContext, while flushing
+	splitMarkers SplitMarkers
+	stripEscapes bool // whether the escape sequences are removed from the output
+	colorMode    ColorMode
+	noColors     bool // whether the styles are not written, set when flushing
+	normalize    bool // whether the cells are normalized to NFC
+	stripANSI    bool // whether the escape sequences are removed from the cells
+	resetStyles  bool // whether the styles left active by the cells are reset
+	padTrailing  bool // whether the last column is always padded to its width
+	trimTrailing bool // whether the trailing whitespace of the lines is removed
+	overflow     OverflowPolicy
+	emptyRows    EmptyRowPolicy
+	caption      Caption
+	clipMarker   string // appended to clipped lines
+	indicator    string // format of the hidden columns indicator
+	testing      bool   // whether the output must not depend on the environment
+	termWidth    func(out io.Writer) (int, bool)
+	autoWidth    bool           // whether the width is detected before flushing
+	resized      chan os.Signal // notified of the resizes of the terminal, if possible
+	live         bool           // whether the output is redrawn in place
+	liveWidths   []int          // widths of the lines of the last flush, in live mode
+	colStyles    []style        // styles of the visible columns, cycled through
+	rowStyles    []style        // styles of the body rows, cycled through
+	rowFilter    func(tag any, cells []string) bool
+	tagFilter    func(tag any) bool
+	rowLess      func(a, b []string) bool
+	rowStyle     func(tag any) *color.Color
+	highlight    *highlight    // pattern highlighted in the cells, if any
+	headers      []any         // cells of the header row, see SetHeaders
+	groups       []ColumnGroup // groups of columns, see SetColumnGroups
+	headerStyle  style         // style of the cells of the header rows
+	headerBreak  bool          // whether the header cells may be broken anywhere
+	headerWrap   *WrapMode     // wrap mode of the header cells, if set
+	headerLines  int           // maximum number of lines of the header cells
+	structType   reflect.Type  // type of the last struct written with WriteStruct
+	streamSample int           // number of rows sampled in streaming mode, if > 0
+	maxBuffered  int           // maximum number of buffered rows, if > 0
+	sectionStyle style         // style of the titles of the section rows
+	stream       *stream       // stream in progress, if any
+	streaming    bool          // whether the rows of a stream are being flushed
+
+	mu     sync.Mutex
+	buffer []byte
+	rows   []row
+}
 
-	mu        sync.Mutex
-	buffer    []byte
-	colBuffer [][]string
+// row is a row of cells in the buffer, or a separator.
+type row struct {
+	cells  []string
+	values []any      // cells whose conversion is deferred until flushing
+	all    []string   // all the cells as written, including the omitted ones
+	opts   []cellOpts // options of the cells, if any were set with AddRow
+	tag    any        // metadata set with WriteRowTagged
+	seq    *uint64    // sequence number set with WriteRowSeq
+	depth  int        // depth in the tree, set with WriteTreeRow
+	indent int        // indentation level, set with WriteRowIndent
+	tree   [2]string  // branches of the first and next lines in tree columns
+	spans  []int      // number of columns spanned by the cells, if any spans more than one
+	kind   RowKind
+	rule   bool  // whether this is a separator written with WriteSeparator
+	group  bool  // whether this is the header row of the column groups
+	stripe int   // index of the body row from 1, set when flushing, see SetAlternatingRows
+	style  style // style of the row from SetRowStyle, set when flushing
+}
+
+// cellOpts are the options of a single cell.
+type cellOpts struct {
+	align   *Alignment // overrides the alignment of the column if not nil
+	style   style
+	content ContentProvider // renders the cell once the width is known
+}
+
+// cellOpts returns the options of the cell of the given index.
+func (r row) cellOpts(colIdx int) cellOpts {
+	if colIdx >= len(r.opts) {
+		return cellOpts{}
+	}
+	return r.opts[colIdx]
 }
 
 // SetColumns sets the configuration for the first len(cols) columns.
@@ -207,9 +651,50 @@ func (w *Writer) SetColumns(cols ...Column) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	w.omittedCols = make([]bool, len(cols))
+	w.colDefs = append([]Column(nil), cols...)
+	w.applyColumns()
+}
+
+// SetColumn sets the configuration of the column of index i (starting at 0),
+// leaving the other columns as they are. If fewer columns were configured, the
+// columns in between use the default column configuration.
+func (w *Writer) SetColumn(i int, col Column) {
+	w.SetColumnRange(i, i+1, col)
+}
+
+// SetColumnRange sets the same configuration for the columns from index from
+// (included) to index to (excluded), leaving the other columns as they are. If
+// fewer columns were configured, the columns in between use the default column
+// configuration.
+func (w *Writer) SetColumnRange(from, to int, col Column) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if from < 0 {
+		from = 0
+	}
+	for len(w.colDefs) < to {
+		w.colDefs = append(w.colDefs, nil)
+	}
+	for i := from; i < to; i++ {
+		w.colDefs[i] = col
+	}
+	w.applyColumns()
+}
+
+// applyColumns computes the flex items of the configured columns.
+func (w *Writer) applyColumns() {
+	w.omittedCols = make([]bool, len(w.colDefs))
 	w.columns = nil
-	for i, col := range cols {
+	for i, col := range w.colDefs {
+		if col == nil {
+			if w.omitDefault {
+				w.omittedCols[i] = true
+				continue
+			}
+			w.columns = append(w.columns, w.defaultCol)
+			continue
+		}
 		if _, ok := col.(Omit); ok {
 			w.omittedCols[i] = true
 			continue
@@ -220,17 +705,47 @@ func (w *Writer) SetColumns(cols ...Column) {
 
 // SetDefaultColumn sets the default column configuration. This configuration is
 // used when more columns are written than are configured with
-// [Writer.SetColumns].
+// [Writer.SetColumns], and for the columns skipped by [Writer.SetColumnRange].
 func (w *Writer) SetDefaultColumn(col Column) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	w.defaultDef = col
 	if _, ok := col.(Omit); ok {
 		w.omitDefault = true
-		return
+	} else {
+		w.omitDefault = false
+		w.defaultCol = col.flex()
+	}
+	// the columns left to the default by SetColumnRange follow it
+	w.applyColumns()
+}
+
+// Columns returns the configuration of the columns configured with
+// [Writer.SetColumns] and [Writer.SetColumnRange], including the omitted ones;
+// the columns left to the default have the default configuration, see
+// [Writer.DefaultColumn].
+func (w *Writer) Columns() []Column {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cols := make([]Column, len(w.colDefs))
+	for i, col := range w.colDefs {
+		if col == nil {
+			col = w.defaultDef
+		}
+		cols[i] = col
 	}
-	w.omitDefault = false
-	w.defaultCol = col.flex()
+	return cols
+}
+
+// DefaultColumn returns the default column configuration, see
+// [Writer.SetDefaultColumn].
+func (w *Writer) DefaultColumn() Column {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.defaultDef
 }
 
 // SetOutput sets the output writer for this flex writer. If the output is a
@@ -241,17 +756,49 @@ func (w *Writer) SetOutput(out io.Writer) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if f, ok := out.(*os.File); ok {
-		if term.IsTerminal(int(f.Fd())) {
-			width, _, err := term.GetSize(int(f.Fd()))
-			if err == nil && width > 0 {
-				w.width = width
-			}
-		}
-	}
+	w.detectWidth(out)
 	w.output = out
 }
 
+// detectWidth sets the width of the flex writer to the width of the terminal
+// that out writes to, if it is one.
+func (w *Writer) detectWidth(out io.Writer) {
+	detect := w.termWidth
+	if detect == nil {
+		detect = TerminalWidth
+	}
+	if width, ok := detect(out); ok && width > 0 {
+		w.width = width
+	}
+}
+
+// TerminalWidth returns the width of the terminal that out writes to, or false
+// if out is not a terminal. This is the default detection function used by
+// [Writer.SetOutput].
+func TerminalWidth(out io.Writer) (int, bool) {
+	f, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0, false
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0, false
+	}
+	return width, true
+}
+
+// SetTerminalWidthFunc sets the function used by [Writer.SetOutput] to detect
+// whether the output is a terminal and get its width; the default is
+// [TerminalWidth]. This is mostly useful in tests, to simulate a terminal
+// without an actual one. As the detection happens in SetOutput, this must be
+// called before it. If detect is nil, the default is restored.
+func (w *Writer) SetTerminalWidthFunc(detect func(out io.Writer) (int, bool)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.termWidth = detect
+}
+
 // SetWidth sets the target width of the output; note however that depending
 // on the columns min width constraints, this may not be honored.
 // The width is also set when the output is set with [Writer.SetOutput] and the
@@ -264,6 +811,26 @@ func (w *Writer) SetWidth(width int) {
 	w.width = width
 }
 
+// SetMaxWidth sets a maximum for the target width: even if the width set with
+// [Writer.SetWidth] or detected by [Writer.SetOutput] is larger, the output
+// will not be wider than maxWidth. This is useful to keep the output readable
+// on very wide terminals, while still shrinking it on narrow ones.
+// If maxWidth is 0 or less, there is no maximum, which is the default.
+func (w *Writer) SetMaxWidth(maxWidth int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.maxWidth = maxWidth
+}
+
+// targetWidth returns the width that the output should match.
+func (w *Writer) targetWidth() int {
+	if w.maxWidth > 0 && w.maxWidth < w.width {
+		return w.maxWidth
+	}
+	return w.width
+}
+
 // SetDecorator sets the decorator for this flex writer.
 func (w *Writer) SetDecorator(deco Decorator) {
 	w.mu.Lock()
@@ -272,6 +839,76 @@ func (w *Writer) SetDecorator(deco Decorator) {
 	w.deco = deco
 }
 
+// EmptyRowPolicy defines how empty rows, i.e. rows written without any cell
+// (or empty lines written with [Writer.Write]), are rendered.
+type EmptyRowPolicy int
+
+const (
+	// EmptyRowKeep renders empty rows like any other row, i.e. as blank rows
+	// with as many empty cells as the other rows. Note that if no row has any
+	// cell, nothing is rendered. This is the default.
+	EmptyRowKeep EmptyRowPolicy = iota
+	// EmptyRowSkip drops the empty rows.
+	EmptyRowSkip
+	// EmptyRowSpacer renders empty rows as blank lines spanning the whole
+	// table (including the column separators of the decorator) that are not
+	// separated from the row above by a row separator, so that they can be
+	// used as vertical spacing. If no row has any cell, they are rendered as
+	// empty lines.
+	EmptyRowSpacer
+)
+
+// SetEmptyRowPolicy sets how the empty rows are rendered. The default is
+// [EmptyRowKeep].
+func (w *Writer) SetEmptyRowPolicy(policy EmptyRowPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.emptyRows = policy
+}
+
+// SetCollapsibleGaps sets whether the gaps between columns may be collapsed
+// when the content doesn't fit in the target width, before any column is
+// shrunk (and its content wrapped). For example, a gap of two spaces can
+// collapse to a single space.
+//
+// Only the column separators made entirely of spaces can collapse, down to a
+// single space; this is meant for decorators that don't draw row separators,
+// such as the [GapDecorator].
+func (w *Writer) SetCollapsibleGaps(collapsible bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.collapseGaps = collapsible
+}
+
+// SetHyphenation enables the hyphenation of the words that are wider than
+// their column: instead of being cut anywhere, they are broken with a hyphen
+// into fragments of at least minFragment characters. The columns can then be
+// narrower than such words. A minFragment of 0 disables the hyphenation, which
+// is the default.
+func (w *Writer) SetHyphenation(minFragment int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if minFragment < 0 {
+		minFragment = 0
+	}
+	w.hyphenation = minFragment
+}
+
+// SetContinuationMarker sets a marker, e.g. "↪ ", that prefixes the lines of a
+// cell after the first one when its content is wrapped, so that they can be
+// told apart from separate rows. The marker counts in the width of the
+// columns; it is not used in columns that are not wider than it. By default,
+// there is no marker.
+func (w *Writer) SetContinuationMarker(marker string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.contMarker = marker
+}
+
 // New creates a new flex writer with the default configuration:
 //   - write to standard output
 //   - a target width equal to the width of the standard output if it's a
@@ -284,9 +921,107 @@ func New() *Writer {
 	writer.SetOutput(os.Stdout)
 	writer.SetDefaultColumn(Shrinkable{})
 	writer.SetDecorator(GapDecorator{Gap: "  "})
+	writer.SetHeaderStyle(color.New(color.Bold))
+	writer.SetPageFooter("page %d/%d")
+	return &writer
+}
+
+// NewForTesting creates a new flex writer with the same configuration as
+// [New], except that the output is always exactly the same regardless of the
+// environment, which is useful for golden tests:
+//   - the target width is the given width, even if the output is a terminal
+//   - no color or any other escape sequence is ever written, even if the
+//     decorator or the cells contain some
+func NewForTesting(width int) *Writer {
+	writer := Writer{testing: true}
+	writer.SetTerminalWidthFunc(func(io.Writer) (int, bool) { return 0, false })
+	writer.SetWidth(width)
+	writer.SetOutput(os.Stdout)
+	writer.SetDefaultColumn(Shrinkable{})
+	writer.SetDecorator(GapDecorator{Gap: "  "})
+	writer.SetPageFooter("page %d/%d")
 	return &writer
 }
 
+// CopyConfigFrom copies the whole configuration of other to the flex writer,
+// e.g. its columns, its decorator, its width, its output and its policies, but
+// not its buffered rows, so that specialized writers can be derived from a
+// common one. Later changes to the configuration of either writer don't affect
+// the other.
+func (w *Writer) CopyConfigFrom(other *Writer) {
+	if w == other {
+		return
+	}
+	// the configuration is copied through a snapshot, so that the locks of
+	// both writers are never held at the same time
+	var snapshot Writer
+	other.mu.Lock()
+	copyConfig(&snapshot, other)
+	autoWidth := other.autoWidth
+	other.mu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	copyConfig(w, &snapshot)
+	// the resizes are notified to each writer separately
+	w.setAutoWidth(autoWidth)
+}
+
+// copyConfig copies the configuration of src to dst; the slices and maps are
+// copied too, as they may be modified in place.
+func copyConfig(dst, src *Writer) {
+	clone := func(s []int) []int {
+		if s == nil {
+			return nil
+		}
+		return append([]int(nil), s...)
+	}
+
+	dst.width, dst.maxWidth, dst.output = src.width, src.maxWidth, src.output
+	dst.colDefs = append([]Column(nil), src.colDefs...)
+	dst.omittedCols = append([]bool(nil), src.omittedCols...)
+	dst.omitDefault = src.omitDefault
+	dst.columns = append([]flexItem(nil), src.columns...)
+	dst.defaultDef, dst.defaultCol = src.defaultDef, src.defaultCol
+	dst.deco, dst.fill, dst.gap, dst.collapseGaps = src.deco, src.fill, src.gap, src.collapseGaps
+	dst.tieBreak = src.tieBreak
+	dst.hyphenation, dst.contMarker, dst.rowIndent = src.hyphenation, src.contMarker, src.rowIndent
+	dst.fixedWidths = clone(src.fixedWidths)
+	dst.plainDelim, dst.redactor = src.plainDelim, src.redactor
+	dst.formatters = nil
+	for col, format := range src.formatters {
+		if dst.formatters == nil {
+			dst.formatters = make(map[int]Formatter)
+		}
+		dst.formatters[col] = format
+	}
+	dst.nilText, dst.errStyle, dst.lazy, dst.mirrored = src.nilText, src.errStyle, src.lazy, src.mirrored
+	dst.frozen, dst.pageSize, dst.pageFooter = clone(src.frozen), src.pageSize, src.pageFooter
+	dst.bands, dst.bandKeys, dst.splitMarkers = src.bands, clone(src.bandKeys), src.splitMarkers
+	dst.stripEscapes, dst.normalize, dst.resetStyles = src.stripEscapes, src.normalize, src.resetStyles
+	dst.colorMode, dst.stripANSI = src.colorMode, src.stripANSI
+	dst.padTrailing, dst.trimTrailing = src.padTrailing, src.trimTrailing
+	dst.overflow, dst.emptyRows, dst.caption = src.overflow, src.emptyRows, src.caption
+	dst.clipMarker, dst.indicator = src.clipMarker, src.indicator
+	dst.testing, dst.termWidth, dst.live = src.testing, src.termWidth, src.live
+	dst.colStyles = append([]style(nil), src.colStyles...)
+	dst.rowStyles = append([]style(nil), src.rowStyles...)
+	dst.rowFilter, dst.rowLess, dst.rowStyle = src.rowFilter, src.rowLess, src.rowStyle
+	dst.tagFilter = src.tagFilter
+	dst.highlight, dst.headerStyle = src.highlight, src.headerStyle
+	dst.headerBreak, dst.headerWrap = src.headerBreak, src.headerWrap
+	dst.headerLines = src.headerLines
+	dst.headers = append([]any(nil), src.headers...)
+	if src.headers == nil {
+		dst.headers = nil
+	}
+	dst.groups = append([]ColumnGroup(nil), src.groups...)
+	dst.structType, dst.streamSample = src.structType, src.streamSample
+	dst.maxBuffered = src.maxBuffered
+	dst.sectionStyle = src.sectionStyle
+}
+
 // Write writes row(s) to the flex writer; rows are delimited by a newline
 // (`\n`) and within a row the columns are delimited by a tab (`\t`).
 // This is mostly compatible with the [text/tabwriter] package.
@@ -306,7 +1041,19 @@ func (w *Writer) Write(b []byte) (int, error) {
 }
 
 // WriteRow writes a single row of cells to the flex writer. If the
-// cells are not strings, they are converted to strings using [fmt.Sprint].
+// cells are not strings, they are converted to strings using [fmt.Sprint], or
+// the formatter of their column (see [Writer.SetFormatter]); pointers are
+// dereferenced. The newlines in the cells are hard line breaks within the
+// row; in left-aligned columns, the indentation of the lines is kept, e.g. for
+// stack traces.
+//
+// A cell can also be a func(width int) string, that is called when flushing
+// with the final width of its column, and that must return the content of the
+// cell, no wider than that width, e.g. a bar that fills the cell. These cells
+// are empty for the sizing of the columns, so they are meant for the columns
+// whose width doesn't depend on their content, e.g. [Flexed] columns. For
+// content that must be taken into account in the sizing, see
+// [ContentProvider].
 //
 // This is the recommended method for writing data to the flex writer.
 //
@@ -320,25 +1067,417 @@ func (w *Writer) WriteRow(cells ...any) {
 	defer w.mu.Unlock()
 
 	w.writeRow(cells...)
+	w.streamRows()
+}
+
+// WriteRowTagged is like [Writer.WriteRow], but also attaches some metadata
+// to the row. The tag is not written to the output, but it is passed to the
+// callbacks set with [Writer.SetRowFilter] and [Writer.SetRowStyle], so that
+// they can work with typed data rather than re-parse the cells.
+func (w *Writer) WriteRowTagged(tag any, cells ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeRow(cells...)
+	w.rows[len(w.rows)-1].tag = tag
+	w.streamRows()
+}
+
+// SetRowFilter sets a function that is called for each row when flushing,
+// with its tag (nil if the row was not written with [Writer.WriteRowTagged])
+// and all its cells, including those of the omitted columns, which can thus be
+// used as hidden keys; the row is dropped if it returns false. The dropped
+// rows are not taken into account when sizing the columns. A nil filter keeps
+// all the rows.
+//
+// The filter is called with the lock of the flex writer held, so it must not
+// call any of its methods.
+func (w *Writer) SetRowFilter(filter func(tag any, cells []string) bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rowFilter = filter
+}
+
+// SetRowTagFilter is like [Writer.SetRowFilter], but the filter is only given
+// the tag of each row, before the row is converted: the rows that it drops are
+// thus never converted when the conversion is deferred with
+// [Writer.SetLazyConversion]. A nil filter keeps all the rows.
+//
+// The filter is called with the lock of the flex writer held, so it must not
+// call any of its methods.
+func (w *Writer) SetRowTagFilter(filter func(tag any) bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tagFilter = filter
+}
+
+// filterTags drops the rows rejected by the tag filter, before they are
+// converted.
+func (w *Writer) filterTags() {
+	var rows []row
+	for _, r := range w.rows {
+		if r.rule || r.kind == HeaderRow || r.kind == FooterRow || w.tagFilter(r.tag) {
+			rows = append(rows, r)
+		}
+	}
+	w.rows = rows
+}
+
+// SetRowSort sets a function that is used to sort the rows when flushing; it
+// returns whether the row of cells a must be written before the row of cells
+// b. The cells include those of the omitted columns, which can thus be used as
+// hidden sort keys, e.g. timestamps. The sort is stable, and the rows are not
+// moved across the separators written with [Writer.WriteSeparator]. A nil
+// function keeps the rows in the order they were written.
+//
+// The function is called with the lock of the flex writer held, so it must not
+// call any of its methods.
+func (w *Writer) SetRowSort(less func(a, b []string) bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rowLess = less
+}
+
+// sortRows sorts the rows between the separators.
+func (w *Writer) sortRows() {
+	start := 0
+	for i := 0; i <= len(w.rows); i++ {
+		if i < len(w.rows) && !w.rows[i].rule {
+			continue
+		}
+		segment := w.rows[start:i]
+		sort.SliceStable(segment, func(a, b int) bool {
+			return w.rowLess(segment[a].all, segment[b].all)
+		})
+		start = i + 1
+	}
+}
+
+// WriteRowSeq is like [Writer.WriteRow], for rows written concurrently by
+// several goroutines, e.g. parallel workers: when flushing, the rows written
+// with WriteRowSeq are put in the order of their sequence number seq, however
+// they arrived. They take the places in the buffer of the rows written with
+// WriteRowSeq, so that they stay e.g. below a first row written with
+// WriteRow. The rows with the same sequence number stay in the order they
+// were written.
+func (w *Writer) WriteRowSeq(seq uint64, cells ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeRow(cells...)
+	w.rows[len(w.rows)-1].seq = &seq
+	w.streamRows()
+}
+
+// orderSeqRows puts the rows written with WriteRowSeq in the order of their
+// sequence numbers.
+func (w *Writer) orderSeqRows() {
+	var positions []int
+	var rows []row
+	for i, r := range w.rows {
+		if r.seq != nil {
+			positions = append(positions, i)
+			rows = append(rows, r)
+		}
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return *rows[a].seq < *rows[b].seq
+	})
+	for i, pos := range positions {
+		w.rows[pos] = rows[i]
+	}
+}
+
+// SetRowStyle sets a function that is called for each row when flushing, with
+// its tag (nil if the row was not written with [Writer.WriteRowTagged]), and
+// returns the color of the row, or nil for no color. The color is applied to
+// the content of the cells along with their padding, in place of the tints of
+// [Writer.SetAlternatingRows] and [Writer.SetAlternatingColumns], but not to
+// the column separators.
+//
+// The function is called with the lock of the flex writer held, so it must
+// not call any of its methods.
+func (w *Writer) SetRowStyle(rowStyle func(tag any) *color.Color) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rowStyle = rowStyle
+}
+
+// WriteRowIndent is like [Writer.WriteRow], but the first visible column is
+// indented by level times the indentation width (2 spaces by default, see
+// [Writer.SetRowIndentWidth]), e.g. to render nested structures. The
+// indentation counts in the width of the column, and it is kept on all the
+// lines if the content is wrapped; indented cells are always left-aligned.
+func (w *Writer) WriteRowIndent(level int, cells ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeRow(cells...)
+	if level > 0 {
+		w.rows[len(w.rows)-1].indent = level
+	}
+	w.streamRows()
+}
+
+// SetRowIndentWidth sets the number of spaces of each level of indentation of
+// [Writer.WriteRowIndent]; the default is 2.
+func (w *Writer) SetRowIndentWidth(width int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rowIndent = width
+}
+
+func (w *Writer) indentWidth() int {
+	if w.rowIndent > 0 {
+		return w.rowIndent
+	}
+	return 2
+}
+
+// SetRedactor sets a function that is called on each cell when it is written,
+// with the index of its column (including the omitted columns, starting at 0)
+// and its value converted to a string, and that returns the value to use
+// instead, e.g. to mask secrets. As the original values are never stored,
+// the redactor only applies to the rows written after it is set; the omitted
+// cells given to the callbacks of [Writer.SetRowFilter] and
+// [Writer.SetRowSort] are redacted too. The cells rendered by a
+// [ContentProvider] have no value to redact, use a [Masked] column to hide
+// them. A nil redactor keeps the values.
+//
+// The redactor is called with the lock of the flex writer held, so it must not
+// call any of its methods.
+func (w *Writer) SetRedactor(redactor func(colIdx int, value string) string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.redactor = redactor
+}
+
+// SetResetStyles sets whether a reset sequence is appended to the content of
+// the cells that leave a style active, e.g. a color code that is never reset,
+// so that it can't bleed into the padding, the column separators and the
+// following cells. By default, the cells are written as they are.
+func (w *Writer) SetResetStyles(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.resetStyles = enabled
+}
+
+// SetPadTrailing sets whether the last column is always padded with spaces to
+// its full width, so that all the lines of the output have the same width,
+// e.g. when they are later pasted side by side. By default, the last column is
+// padded only if there is a right column separator or if it is styled, so
+// that the lines don't end with useless spaces.
+func (w *Writer) SetPadTrailing(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.padTrailing = enabled
+}
+
+// SetTrimTrailing sets whether the trailing whitespace is removed from the
+// lines written, once they are decorated, e.g. so that a right column
+// separator made of spaces doesn't end up in git diffs or logs. The escape
+// sequences, such as colors, are kept. This takes precedence over
+// [Writer.SetPadTrailing]. By default, the lines are written as they are.
+func (w *Writer) SetTrimTrailing(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.trimTrailing = enabled
+}
+
+// SetLazyConversion sets whether the cells are converted to strings when they
+// are flushed rather than when they are written, i.e. when their String
+// method, their formatter (see [Writer.SetFormatter]), etc. are called. This
+// makes writing the rows cheaper, e.g. in a loop that must not be slowed
+// down, and the rows that are never flushed are never converted; but the
+// values must then not be modified until they are flushed, in particular the
+// values behind pointers. Note that the rows are converted before they are
+// given to the filter of [Writer.SetRowFilter], but not to the one of
+// [Writer.SetRowTagFilter]. The options of the
+// conversion, e.g. [Writer.SetRedactor], apply as they are set when flushing.
+// By default, the cells are converted when they are written.
+func (w *Writer) SetLazyConversion(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lazy = enabled
+}
+
+// SetStripANSI sets whether the escape sequences, e.g. colors or hyperlinks,
+// are removed from the cells when they are written, e.g. to write clean plain
+// text when the output is a file or a pipe. The widths of the columns are then
+// computed from the stripped cells. The headers and the section titles are
+// stripped too, but the styles set on the flex writer (see
+// [Writer.SetColorMode]) and the decorator are left as they are. By default,
+// the cells are written as they are.
+func (w *Writer) SetStripANSI(strip bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stripANSI = strip
+}
+
+// SetNormalization sets whether the cells are normalized to the Unicode NFC
+// form when they are written. Text in the decomposed form, e.g. file names on
+// macOS, has its accents as separate combining characters, that the wrapping
+// may separate from their letters; once normalized, the accented letters are
+// single characters. By default, the cells are written as they are.
+func (w *Writer) SetNormalization(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.normalize = enabled
+}
+
+// WriteRows writes several rows of cells to the flex writer at once, like
+// as many calls to [Writer.WriteRow] would. This is convenient to write e.g.
+// the results of a query.
+func (w *Writer) WriteRows(rows [][]any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.growRows(len(rows))
+	for _, cells := range rows {
+		w.writeRow(cells...)
+	}
+	w.streamRows()
+}
+
+// WriteStringRows is like [Writer.WriteRows] for rows of strings.
+func (w *Writer) WriteStringRows(rows [][]string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.growRows(len(rows))
+	for _, cells := range rows {
+		w.writeRow(transform(cells, func(s string) any { return s })...)
+	}
+	w.streamRows()
+}
+
+// growRows makes room in the buffer for n more rows, so that writing many
+// rows at once doesn't grow it several times. The buffer is at least doubled,
+// as with append, so that writing the rows in small batches stays linear.
+func (w *Writer) growRows(n int) {
+	if len(w.rows)+n <= cap(w.rows) {
+		return
+	}
+	size := len(w.rows) + n
+	if size < 2*cap(w.rows) {
+		size = 2 * cap(w.rows)
+	}
+	rows := make([]row, len(w.rows), size)
+	copy(rows, w.rows)
+	w.rows = rows
 }
 
 func (w *Writer) writeRow(cells ...any) {
-	var filteredCells []any
-	for i, cell := range cells {
-		if w.isOmitted(i) {
+	w.writeRowOpts(cells, nil)
+}
+
+// writeRowOpts writes a row whose cells have the given options; opts may be
+// shorter than cells.
+func (w *Writer) writeRowOpts(cells []any, opts []cellOpts) {
+	if w.lazy {
+		// the cells are copied as the caller may reuse the slice
+		w.rows = append(w.rows, row{values: append([]any{}, cells...), opts: opts})
+		return
+	}
+	w.rows = append(w.rows, w.convertRow(cells, opts))
+}
+
+// convertRows converts the cells of the rows whose conversion was deferred by
+// [Writer.SetLazyConversion].
+func (w *Writer) convertRows() {
+	for i, r := range w.rows {
+		if r.values == nil {
 			continue
 		}
-		filteredCells = append(filteredCells, cell)
+		converted := w.convertRow(r.values, r.opts)
+		r.cells, r.all, r.opts, r.values = converted.cells, converted.all, converted.opts, nil
+		r.spans = converted.spans
+		w.rows[i] = r
 	}
+}
 
-	toString := func(a any) string {
-		if s, ok := a.(string); ok {
-			return s
+// convertRow converts the cells of a row to strings.
+func (w *Writer) convertRow(cells []any, opts []cellOpts) row {
+	cells, opts, spans := expandSpans(cells, opts)
+	all := make([]string, len(cells))
+	for i, a := range cells {
+		if content, ok := contentProvider(a); ok {
+			for len(opts) <= i {
+				opts = append(opts, cellOpts{})
+			}
+			opts[i].content = content
+			continue
+		}
+		all[i] = w.cellString(i, a)
+		if _, ok := a.(error); ok && !w.errStyle.isZero() {
+			for len(opts) <= i {
+				opts = append(opts, cellOpts{})
+			}
+			if opts[i].style.isZero() {
+				opts[i].style = w.errStyle
+			}
+		}
+	}
+	if w.normalize {
+		all = transform(all, norm.NFC.String)
+	}
+	if w.stripANSI {
+		all = transform(all, textutil.StripEscapes)
+	}
+	if w.redactor != nil {
+		for i := range all {
+			all[i] = w.redactor(i, all[i])
 		}
-		return fmt.Sprint(a)
 	}
-	scells := transform(filteredCells, toString)
-	w.colBuffer = append(w.colBuffer, scells)
+
+	var filteredCells []string
+	var filteredOpts []cellOpts
+	for i := range all {
+		if w.isOmitted(i) {
+			continue
+		}
+		if mask := w.getColumnDef(len(filteredCells)).mask; mask != "" {
+			// the content providers are masked too, rather than rendered
+			if i < len(opts) && opts[i].content != nil {
+				all[i], opts[i].content = mask, nil
+			} else if all[i] != "" {
+				all[i] = mask
+			}
+		}
+		filteredCells = append(filteredCells, all[i])
+		if i < len(opts) {
+			filteredOpts = append(filteredOpts, opts[i])
+		}
+	}
+
+	return row{cells: filteredCells, all: all, opts: filteredOpts, spans: w.visibleSpans(spans, len(all))}
+}
+
+// WriteSeparator writes a horizontal separator between the rows written
+// before and after it. If the decorator already separates these rows, e.g. a
+// table decorator that separates all rows, nothing more is added; otherwise,
+// the separator is drawn by the decorator if it is a [RuleDecorator], or it is
+// a line of "─" spanning the whole width of the output.
+//
+// This is useful to delimit groups of rows, especially with a [GapDecorator].
+func (w *Writer) WriteSeparator() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushBuffer()
+	w.rows = append(w.rows, row{rule: true})
 }
 
 func (w *Writer) isOmitted(i int) bool {
@@ -348,54 +1487,66 @@ func (w *Writer) isOmitted(i int) bool {
 	return w.omitDefault
 }
 
+// writtenIndex returns the index, among the written cells, of the visible
+// column of the given index.
+func (w *Writer) writtenIndex(colIdx int) int {
+	i := 0
+	for ; ; i++ {
+		if w.isOmitted(i) {
+			continue
+		}
+		if colIdx == 0 {
+			return i
+		}
+		colIdx--
+	}
+}
+
 func (w *Writer) getColumnDef(i int) flexItem {
-	if i < len(w.columns) {
-		return w.columns[i]
+	return w.getColumnDefAt(w.columns, i)
+}
+
+// getColumnDefAt is like getColumnDef, for the given visible columns.
+func (w *Writer) getColumnDefAt(columns []flexItem, i int) flexItem {
+	if i < len(columns) {
+		return columns[i]
 	}
 	return w.defaultCol
 }
 
 func (w *Writer) colMinContent(colIdx int) int {
-	return max(transform(w.colBuffer, func(row []string) int {
-		if colIdx >= len(row) {
+	return max(transform(w.rows, func(r row) int {
+		if colIdx >= len(r.cells) || r.span(colIdx) != 1 {
 			return 0
 		}
-		return minContent(row[colIdx])
-	}))
-}
-
-func (w *Writer) computeWidths() []int {
-	rowColLengths := transform(w.colBuffer, func(rows []string) []int {
-		return transform(rows, text.Len)
-	})
-	colRowLengths := transpose(rowColLengths)
-	colLengths := transform(colRowLengths, max)
-
-	nColumns := len(colLengths)
-
-	flexItems := make([]flex.Item, nColumns)
-	for i := 0; i < nColumns; i++ {
-		col := w.getColumnDef(i)
-
-		var minSize int
-		if col.Min > 0 {
-			minSize = col.Min
-		} else {
-			minSize = w.colMinContent(i)
+		if content := r.cellOpts(colIdx).content; content != nil {
+			return content.MinWidth()
 		}
-		if col.Max > 0 && minSize > col.Max {
-			minSize = col.Max
+		if r.kind == HeaderRow && w.headerBreak {
+			return 1
 		}
-		it := col.Item
-		it.Min = minSize
-		it.Size = colLengths[i]
-
-		flexItems[i] = it
-	}
-
-	freeSpace := w.width - decoratorWidth(w.deco, nColumns)
-
-	return flex.ResolveFlexLengths(flexItems, freeSpace)
+		def := w.headerDef(w.getColumnDef(colIdx), r.kind)
+		switch def.wrap {
+		case WrapChar:
+			return 1
+		case WrapNone:
+			return textutil.Width(r.cells[colIdx])
+		}
+		cell := textutil.MarkBreaks(r.cells[colIdx], def.breakAfter)
+		minContent := textutil.HyphenatedMinContent(cell, w.hyphenation)
+		if first, next := w.cellPrefixes(r, colIdx); first != "" || next != "" {
+			// there must be room for the prefixes and some content
+			if minContent < 1 {
+				minContent = 1
+			}
+			prefix := textutil.Width(first)
+			if textutil.Width(next) > prefix {
+				prefix = textutil.Width(next)
+			}
+			minContent += prefix
+		}
+		return minContent
+	}))
 }
 
 func (w *Writer) flushBuffer() {
@@ -405,6 +1556,10 @@ func (w *Writer) flushBuffer() {
 		rows = rows[:len(rows)-1]
 	}
 	for _, row := range rows {
+		if row == "" {
+			w.writeRow()
+			continue
+		}
 		var cells []any
 		for _, cell := range strings.Split(row, "\t") {
 			cells = append(cells, cell)
@@ -415,68 +1570,624 @@ func (w *Writer) flushBuffer() {
 }
 
 // Flush writes the contents of the internal buffer to the output. This also
-// resets the internal buffer and the associated column widths.
+// resets the internal buffer and the associated column widths. If the output
+// fails, a *[WriteError] is returned.
 func (w *Writer) Flush() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	_, err := w.flush()
+	return err
+}
+
+// Stats holds information about the current state of a writer.
+type Stats struct {
+	// BufferedRows is the number of rows written since the last flush, not
+	// counting separators.
+	BufferedRows int
+	// Columns is the number of columns configured with [Writer.SetColumns],
+	// including the omitted ones.
+	Columns int
+	// Width is the target width of the output.
+	Width int
+	// FixedWidths is whether the widths of the columns are fixed by
+	// [Writer.SetFixedWidths].
+	FixedWidths bool
+}
+
+// Stats returns information about the current state of the writer.
+func (w *Writer) Stats() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var rows int
+	for _, r := range w.rows {
+		if !r.rule {
+			rows++
+		}
+	}
+	// rows written with Write are only parsed when flushing
+	rows += bytes.Count(w.buffer, []byte("\n"))
+	if len(w.buffer) > 0 && w.buffer[len(w.buffer)-1] != '\n' {
+		rows++
+	}
+
+	return Stats{
+		BufferedRows: rows,
+		Columns:      len(w.omittedCols),
+		Width:        w.targetWidth(),
+		FixedWidths:  len(w.fixedWidths) > 0,
+	}
+}
+
+// FlushStats holds information about the output of a flush.
+type FlushStats struct {
+	// Rows is the number of rows written, not counting separators.
+	Rows int
+	// Lines is the number of lines written, including separators.
+	Lines int
+	// Widths are the widths of the columns, not including the decorator.
+	Widths []int
+	// Wrapped is the number of columns in which some content was wrapped.
+	Wrapped int
+	// Hidden is the number of columns that were not entirely visible because
+	// of the [OverflowClip] policy, or that were dropped because of their
+	// Priority.
+	Hidden int
+}
+
+// FlushContext is like [Writer.Flush], but stops writing the rows if the
+// context is cancelled, e.g. when the output is a network connection that
+// stalls; the rows are then written one at a time, and the check is made
+// before each of them. If the flush is interrupted, the rows that are not
+// written yet are discarded, and the error of the context is returned. If
+// the context is already cancelled, nothing is written and the rows are kept.
+func (w *Writer) FlushContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.flushCtx = ctx
+	defer func() { w.flushCtx = nil }()
+	_, err := w.flush()
+	return err
+}
+
+// FlushStats is like [Writer.Flush], but also returns information about what
+// was written.
+func (w *Writer) FlushStats() (FlushStats, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.flush()
+}
+
+// Render is like [Writer.Flush], but returns the output instead of writing it
+// to the output of the writer, e.g. to embed a table in a larger message. The
+// target width is the same as when flushing.
+func (w *Writer) Render() (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// the width is detected with the actual output
+	w.refreshWidth()
+	var buf bytes.Buffer
+	output := w.output
+	w.output = &buf
+	defer func() { w.output = output }()
+
+	if _, err := w.flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderLines is like [Writer.Render], but returns the lines of the output,
+// without their newlines.
+func (w *Writer) RenderLines() ([]string, error) {
+	out, err := w.Render()
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(out, "\n"), "\n"), nil
+}
+
+// prepareRows parses the rows written with Write, converts the cells whose
+// conversion was deferred, filters and sorts the buffered rows, and adds the
+// header row.
+func (w *Writer) prepareRows() {
 	w.flushBuffer()
-	widths := w.computeWidths()
+	if w.tagFilter != nil {
+		w.filterTags()
+	}
+	w.convertRows()
+	w.orderSeqRows()
+	// the rows may be prepared again, e.g. for each band or when a flush is
+	// retried; the header rows are then already there, and must stay first
+	var headers []row
+	for len(w.rows) > 0 && !w.rows[0].rule && w.rows[0].kind == HeaderRow {
+		headers = append(headers, w.rows[0])
+		w.rows = w.rows[1:]
+	}
+	// the footer rows are set aside, to be written last
+	var rows, footers []row
+	for _, r := range w.rows {
+		if !r.rule && r.kind == FooterRow {
+			footers = append(footers, r)
+		} else {
+			rows = append(rows, r)
+		}
+	}
+	if footers != nil {
+		w.rows = rows
+	}
+	if w.emptyRows == EmptyRowSkip || w.rowFilter != nil {
+		var rows []row
+		for _, r := range w.rows {
+			if r.rule {
+				rows = append(rows, r)
+				continue
+			}
+			if w.emptyRows == EmptyRowSkip && len(r.cells) == 0 {
+				continue
+			}
+			if w.rowFilter != nil && !w.rowFilter(r.tag, r.all) {
+				continue
+			}
+			rows = append(rows, r)
+		}
+		w.rows = rows
+	}
+	if w.rowLess != nil {
+		w.sortRows()
+	}
+	if headers != nil {
+		w.rows = append(headers, w.rows...)
+	} else if !w.streamContinues() {
+		w.addHeaders()
+	}
+	w.rows = append(w.rows, footers...)
+}
+
+func (w *Writer) flush() (FlushStats, error) {
+	w.refreshWidth()
+	if w.live && !w.inBand {
+		if err := w.clearLive(); err != nil {
+			return FlushStats{}, &WriteError{Kept: true, Err: err}
+		}
+		defer w.trackLive()()
+	}
+	if w.stream != nil && !w.streaming {
+		// this flush ends the stream in progress
+		if err := w.stream.err; err != nil {
+			w.stream = nil
+			return FlushStats{}, err
+		}
+		defer func() { w.stream = nil }()
+	}
+	w.prepareRows()
+	track := outputTracker{rows: w.rows}
+	if w.plainDelim != "" {
+		return w.flushPlain()
+	}
+	if w.bands && !w.inBand {
+		return w.flushBands()
+	}
+	l, restore, err := w.resolveLayout()
+	defer restore()
+	if err != nil {
+		return FlushStats{}, err
+	}
+	w.noColors = !w.colorsEnabled()
+	if w.noColors {
+		l.deco = noColorDecorator{l.deco}
+	}
+	widths := l.widths
+	w.lastWidths = w.configWidths(widths)
+	if w.streaming && w.stream.widths == nil {
+		// the widths of the sample are kept for the rest of the stream
+		w.stream.widths = append([]int(nil), w.lastWidths...)
+		w.stream.outer = l.outerWidths()
+	}
+
+	if w.overflow == OverflowError {
+		if err := w.checkOverflow(l); err != nil {
+			return FlushStats{}, err
+		}
+	}
+
+	var indicator string
+	if w.indicator != "" && (w.overflow == OverflowClip || l.dropped > 0) {
+		indicator = w.overflowIndicator(l)
+	}
+
+	width := w.targetWidth()
+	stats := FlushStats{Widths: widths, Hidden: l.dropped}
+	if w.overflow == OverflowClip {
+		stats.Hidden += w.hiddenColumns(l, width)
+	}
+	wrapped := make([]bool, len(widths))
 
 	var out bytes.Buffer
+	writeLine := func(line string) {
+		if w.testing || w.stripEscapes {
+			line = textutil.StripEscapes(line)
+		}
+		if w.overflow == OverflowClip {
+			line = textutil.Truncate(line, width, w.clipMarker)
+		}
+		if w.trimTrailing {
+			line = textutil.TrimRight(line)
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+		stats.Lines++
+	}
+	writeRowLine := func(line string) {
+		if indicator != "" {
+			line = textutil.Truncate(line, width-textutil.Width(indicator), "")
+			line = textutil.Align(line, width-textutil.Width(indicator), Left, true) + indicator
+		}
+		writeLine(line)
+	}
 
-	if hdr := w.deco.RowSeparator(0, widths); hdr != "" {
-		out.WriteString(hdr + "\n")
+	var nRows int
+	for _, r := range w.rows {
+		if !r.rule {
+			nRows++
+		}
+	}
+
+	caption, err := w.captionLines(l)
+	if err != nil {
+		return FlushStats{}, fmt.Errorf("flexwriter: cannot wrap the caption: %w", err)
 	}
-	for ri, row := range w.colBuffer {
-		if ri == len(w.colBuffer)-1 {
+	if !w.caption.Below && !w.streamContinues() {
+		for _, line := range caption {
+			writeLine(line)
+		}
+	}
+
+	// whether the last line written is a separator
+	separated := false
+	var ri, stripes int
+	top, above, outer := 0, NoRow, l.outerWidths()
+	var joined []bool
+	if w.streamContinues() {
+		// the rows follow the last one written by the stream
+		ri, above, joined = w.stream.rows, w.stream.kind, w.stream.joined
+		stripes = w.stream.stripes
+		top = ri
+		if nRows == 0 {
+			// only the bottom border remains, of the width of the stream
+			top, outer = -1, w.stream.outer
+		}
+	}
+	last := ri + nRows
+	if nRows > 0 || !w.streaming {
+		if hdr := rowSeparatorSpan(l.deco, top, above, w.nextRowKind(-1), outer, joined, w.nextJoined(-1, len(outer))); hdr != "" {
+			writeLine(hdr)
+			separated = true
+		}
+	}
+	for idx, r := range w.rows {
+		if !r.rule {
+			track.startRow(track.written + out.Len())
+		}
+		if w.flushCtx != nil {
+			// with a context, the rows are written one at a time, so that a
+			// slow output doesn't delay the cancellation
+			if err := track.write(w, out.Bytes()); err != nil {
+				return FlushStats{}, err
+			}
+			out.Reset()
+			if err := w.flushCtx.Err(); err != nil {
+				w.rows = nil
+				return stats, err
+			}
+		}
+		if r.rule {
+			if !separated {
+				if sep := ruleSeparator(l.deco, ri, above, w.nextRowKind(idx), l.outerWidths()); sep != "" {
+					writeLine(sep)
+				}
+				separated = true
+			}
+			continue
+		}
+
+		kind := w.rowKind(r)
+		above = kind
+		ri++
+		stats.Rows++
+		if ri == last && !w.streaming {
 			ri = -1
-		} else {
-			ri += 1
 		}
-		if len(row) < len(widths) {
+		separated = false
+		if kind == BodyRow {
+			stripes++
+			r.stripe = stripes
+		}
+		if w.rowStyle != nil && !w.noColors {
+			r.style = newStyle(w.rowStyle(r.tag))
+		}
+		if w.streaming {
+			w.stream.rows, w.stream.kind = ri, kind
+			w.stream.joined = r.joined(len(widths))
+			w.stream.stripes = stripes
+		}
+
+		if len(r.cells) == 0 && len(widths) == 0 && w.emptyRows == EmptyRowSpacer {
+			writeLine("")
+			continue
+		}
+		cells := r.cells
+		if len(cells) < len(widths) {
 			// pad rows with missing columns
-			row = append(row, make([]string, len(widths)-len(row))...)
+			cells = append(cells, make([]string, len(widths)-len(cells))...)
+		}
+
+		// the width of each cell, and the last column it spans, or -1 if it is
+		// covered by a spanning cell
+		cellWidths := append([]int(nil), widths...)
+		ends := make([]int, len(cells))
+		for ci := range cells {
+			ends[ci] = ci
+			switch span := r.span(ci); {
+			case span == 0:
+				ends[ci] = -1
+			case span > 1:
+				ends[ci] = ci + span - 1
+				if ends[ci] >= len(widths) {
+					ends[ci] = len(widths) - 1
+				}
+				cellWidths[ci] = spanWidth(l, ci, ends[ci])
+			}
 		}
 
-		wrappedCols := make([][]string, len(row))
-		for ci, col := range row {
-			wrappedCols[ci] = wrap(col, widths[ci])
+		wrappedCols := make([][]string, len(cells))
+		var nLines int
+		for ci, col := range cells {
+			if ends[ci] == -1 {
+				continue
+			}
+			if content := r.cellOpts(ci).content; content != nil {
+				// the rendered lines are trusted to fit, they are not wrapped
+				wrappedCols[ci] = content.Render(cellWidths[ci])
+				cells[ci] = strings.Join(wrappedCols[ci], " ")
+			} else {
+				wrappedCols[ci], err = w.wrapCell(r, ci, col, cellWidths[ci])
+				if err != nil {
+					return FlushStats{}, fmt.Errorf("flexwriter: cannot wrap row %d, column %d: %w", stats.Rows, ci+1, err)
+				}
+			}
+			if len(wrappedCols[ci]) > 1 {
+				wrapped[ci] = true
+			}
+			if len(wrappedCols[ci]) > nLines {
+				nLines = len(wrappedCols[ci])
+			}
+		}
+		for ci := range wrappedCols {
+			// the tree branches go on along the lines of the other columns
+			for ends[ci] != -1 && w.getColumnDef(ci).tree && len(wrappedCols[ci]) < nLines {
+				_, next := w.cellPrefixes(r, ci)
+				wrappedCols[ci] = append(wrappedCols[ci], next)
+			}
 		}
 		transposed := transpose(wrappedCols)
 		for _, line := range transposed {
-			out.WriteString(w.deco.ColumnSeparator(ri, 0))
+			var sb strings.Builder
+			sb.WriteString(columnSeparator(l.deco, ri, 0, kind))
+			sb.WriteString(strings.Repeat(" ", l.indent))
 			for ci, col := range line {
-				colAlign := w.getColumnDef(ci).Alignment
-				if ci != len(line)-1 {
-					out.WriteString(align(col, widths[ci], colAlign, true))
-					out.WriteString(w.deco.ColumnSeparator(ri, ci+1))
+				end := ends[ci]
+				if end == -1 {
+					continue
+				}
+				def := w.getColumnDef(ci)
+				colAlign := def.Alignment
+				colStyle := w.tint(r, ci)
+				cellStyle := w.cellStyle(r, ci)
+				opts := r.cellOpts(ci)
+				if opts.align != nil {
+					colAlign = *opts.align
+				}
+				align := func(padRight bool) string {
+					aligned := textutil.Align(col, cellWidths[ci], colAlign, padRight)
+					first, next := w.cellPrefixes(r, ci)
+					// the indentation of the lines of a left-aligned cell with
+					// newlines, e.g. a stack trace, is kept
+					preformatted := colAlign == Left && strings.Contains(cells[ci], "\n")
+					if first != "" || next != "" || preformatted {
+						// the spaces of the prefixes must not be trimmed
+						aligned = col
+						if !padRight {
+							aligned = strings.TrimRight(col, " ")
+						} else if pad := cellWidths[ci] - textutil.Width(col); pad > 0 {
+							aligned += strings.Repeat(" ", pad)
+						}
+					}
+					if def.link != "" {
+						aligned = linkCell(aligned, cellURL(def.link, cells[ci]))
+					}
+					if cellStyle.isZero() {
+						return aligned
+					}
+					// the column style is restarted after the cell style
+					return cellStyle.apply(aligned) + colStyle.in
+				}
+				if end != len(line)-1 {
+					sb.WriteString(colStyle.apply(align(true) +
+						strings.Repeat(" ", l.pads[end])))
+					sb.WriteString(columnSeparator(l.deco, ri, end+1, kind))
 				} else {
 					// last column is right-padded with spaces only if there is
-					// a right separator, otherwise we avoid adding the extra
-					// trailing spaces
-					rightSep := w.deco.ColumnSeparator(ri, -1)
-					if rightSep != "" {
-						out.WriteString(align(col, widths[ci], colAlign, true))
-						out.WriteString(rightSep)
+					// a right separator or it is tinted, otherwise we avoid
+					// adding the extra trailing spaces, unless asked to
+					rightSep := columnSeparator(l.deco, ri, -1, kind)
+					if rightSep != "" || !colStyle.isZero() || w.padTrailing {
+						sb.WriteString(colStyle.apply(align(true) +
+							strings.Repeat(" ", l.pads[end])))
+						sb.WriteString(rightSep)
 					} else {
-						out.WriteString(align(col, widths[ci], colAlign, false))
+						sb.WriteString(align(false))
 					}
 				}
 			}
-			out.WriteByte('\n')
+			writeRowLine(sb.String())
 		}
 
-		if sep := w.deco.RowSeparator(ri, widths); sep != "" {
-			out.WriteString(sep + "\n")
+		next := w.nextRowKind(idx)
+		if next == SpacerRow || (next == NoRow && w.streaming) {
+			// spacers are not separated from the row above, and the
+			// separator below the last row of a stream is written with the
+			// next rows
+			continue
+		}
+		outer := l.outerWidths()
+		if sep := rowSeparatorSpan(l.deco, ri, kind, next, outer, r.joined(len(outer)), w.nextJoined(idx, len(outer))); sep != "" {
+			writeLine(sep)
+			separated = true
 		}
 	}
 
-	_, err := w.output.Write(out.Bytes())
-	if err != nil {
-		return err
+	track.startRow(track.written + out.Len())
+
+	if w.caption.Below && !w.streaming {
+		for _, line := range caption {
+			writeLine(line)
+		}
+	}
+
+	if err := track.write(w, out.Bytes()); err != nil {
+		return FlushStats{}, err
+	}
+
+	for _, wr := range wrapped {
+		if wr {
+			stats.Wrapped++
+		}
+	}
+	w.rows = nil
+	return stats, nil
+}
+
+// cellPrefixes returns the prefixes of the first line and of the next lines
+// of the cell of the given column of a row: its indentation and tree branches.
+func (w *Writer) cellPrefixes(r row, colIdx int) (first, next string) {
+	if colIdx == 0 && r.indent > 0 {
+		first = strings.Repeat(" ", r.indent*w.indentWidth())
+		next = first
+	}
+	if w.getColumnDef(colIdx).tree {
+		first += r.tree[0]
+		next += r.tree[1]
+	}
+	return first, next
+}
+
+// wrapCell wraps the cell of the given column of a row to the given width.
+// The newlines in the cell are hard line breaks: the text between them is
+// wrapped separately, and its continuation lines are not marked.
+func (w *Writer) wrapCell(r row, colIdx int, cell string, width int) ([]string, error) {
+	def := w.headerDef(w.getColumnDef(colIdx), r.kind)
+	first, next := w.cellPrefixes(r, colIdx)
+
+	cell = w.highlightCell(r, colIdx, cell)
+	if !strings.Contains(cell, "\n") {
+		lines, err := w.wrapText(def, cell, width, first, next+w.contMarker)
+		if err != nil {
+			return nil, err
+		}
+		return w.clipHeader(r, lines, width), nil
+	}
+	var lines []string
+	var state text.EscapeState
+	for i, par := range strings.Split(strings.ReplaceAll(cell, "\r\n", "\n"), "\n") {
+		indent := first
+		if i > 0 {
+			indent = next
+		}
+		// the style of the previous lines carries over
+		par = state.FormatString() + par
+		state.Witness(par)
+		wrapped, err := w.wrapText(def, par, width, indent, next+w.contMarker)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, transform(wrapped, textutil.CloseStyles)...)
 	}
+	return w.clipHeader(r, lines, width), nil
+}
 
-	w.colBuffer = nil
+// wrapText wraps some text of a cell of the column to the given width; the
+// first line is prefixed with indent, and the next ones with pad.
+func (w *Writer) wrapText(def flexItem, s string, width int, indent, pad string) ([]string, error) {
+	var lines []string
+	switch def.wrap {
+	case WrapNone:
+		lines = []string{indent + textutil.Truncate(s, width-textutil.Width(indent), w.clipMarker)}
+	case WrapChar:
+		// any character is a break opportunity, so no word is hyphenated
+		var err error
+		s = textutil.MarkBreaks(s, func(rune) bool { return true })
+		lines, err = textutil.WrapIndent(s, width, indent, pad)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		s = textutil.MarkBreaks(s, def.breakAfter)
+		// the hyphenated fragments must fit on all the lines
+		hyphenWidth := width
+		if prefix := textutil.Width(indent); prefix < hyphenWidth {
+			hyphenWidth = width - prefix
+		}
+		if prefix := textutil.Width(pad); prefix < width && width-prefix < hyphenWidth {
+			hyphenWidth = width - prefix
+		}
+		s = textutil.Hyphenate(s, hyphenWidth, w.hyphenation)
+		var err error
+		lines, err = textutil.WrapIndent(s, width, indent, pad)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if w.resetStyles {
+		lines = transform(lines, textutil.CloseStyles)
+	}
+	return lines, nil
+}
+
+// rowKind returns the kind of a row, as passed to the decorator.
+func (w *Writer) rowKind(r row) RowKind {
+	if len(r.cells) == 0 && w.emptyRows == EmptyRowSpacer {
+		return SpacerRow
+	}
+	return r.kind
+}
+
+// nextRowKind returns the kind of the first row after the row at idx,
+// ignoring separators, or NoRow if there is none.
+func (w *Writer) nextRowKind(idx int) RowKind {
+	for _, r := range w.rows[idx+1:] {
+		if !r.rule {
+			return w.rowKind(r)
+		}
+	}
+	return NoRow
+}
+
+// nextJoined returns the boundaries joined by spanning cells, see row.joined,
+// of the first row after the one of the given index that is not a separator,
+// or nil if there is none.
+func (w *Writer) nextJoined(idx, n int) []bool {
+	for _, r := range w.rows[idx+1:] {
+		if !r.rule {
+			return r.joined(n)
+		}
+	}
 	return nil
 }
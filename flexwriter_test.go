@@ -2,12 +2,15 @@ package flexwriter
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"text/tabwriter"
 
@@ -154,6 +157,88 @@ func TestFlexed(t *testing.T) {
 	assertGolden(t, buf.String(), "flexed.txt")
 }
 
+func TestFractionalWeights(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(27)
+	writer.SetColumns(
+		Flexed{Weight: 1.5},
+		Flexed{Weight: 1},
+	)
+
+	writer.WriteRow("a", "b")
+	stats, err := writer.FlushStats()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{15, 10}, stats.Widths)
+
+	writer.SetColumns(
+		Shrinkable{Weight: 1.5},
+		Shrinkable{Weight: 0.5},
+	)
+	writer.WriteRow("aaaa aaaa aaaa aaaa", "bbbb bbbb bbbb bbbb")
+	stats, err = writer.FlushStats()
+	assert.NoError(t, err)
+	// the 13 extra cells are shrunk 3 times more in the first column
+	assert.Equal(t, []int{9, 16}, stats.Widths)
+}
+
+func TestPercent(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(52)
+	writer.SetColumns(
+		Percent{N: 40},
+		Percent{N: 10},
+		Flexed{},
+	)
+
+	writer.WriteRow("a", "a longword", "b")
+	stats, err := writer.FlushStats()
+	assert.NoError(t, err)
+	// 40% and 10% of the 48 columns left by the separators, but the second
+	// column is not narrower than its longest word
+	assert.Equal(t, []int{19, 8, 21}, stats.Widths)
+
+	// the basis of a Flexbox can also be a percentage
+	writer.SetColumns(Flexbox{BasisPercent: 25}, Flexbox{BasisPercent: 50, Grow: 1})
+	writer.WriteRow("a", "b")
+	stats, err = writer.FlushStats()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{12, 38}, stats.Widths)
+}
+
+func TestSetColumnRange(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDefaultColumn(Rigid{Align: Right})
+	writer.SetColumns(Omit{})
+	writer.SetColumnRange(2, 4, Omit{})
+	writer.SetColumn(5, Rigid{})
+
+	writer.WriteRow("a", "b", "c", "d", "e", "f", "g")
+	writer.WriteRow("A", "BB", "C", "D", "EE", "FF", "GG")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		" b   e  f    g\n"+
+		"BB  EE  FF  GG\n", buf.String())
+}
+
+func TestColumns(t *testing.T) {
+	writer := New()
+	assert.Empty(t, writer.Columns())
+	assert.Equal(t, Shrinkable{}, writer.DefaultColumn())
+
+	writer.SetColumns(Rigid{Max: 10}, Omit{})
+	writer.SetColumn(3, Flexed{})
+	writer.SetDefaultColumn(Omit{})
+	assert.Equal(t, []Column{Rigid{Max: 10}, Omit{}, Omit{}, Flexed{}}, writer.Columns())
+	assert.Equal(t, Omit{}, writer.DefaultColumn())
+}
+
 func TestDefaultColumn(t *testing.T) {
 	var buf bytes.Buffer
 	writer := New()
@@ -239,6 +324,12 @@ func TestTableDecorator(t *testing.T) {
 }
 
 func TestTableDecoratorColor(t *testing.T) {
+	// colors are disabled by default when the standard output is not a
+	// terminal, which is the case when running tests
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
 	var buf bytes.Buffer
 	writer := New()
 	writer.SetOutput(&buf)
@@ -255,6 +346,68 @@ func TestTableDecoratorColor(t *testing.T) {
 	assertGolden(t, buf.String(), "tablecolor.txt")
 }
 
+func TestAlternatingColumns(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetDefaultColumn(Rigid{})
+	writer.SetAlternatingColumns(color.New(color.BgBlue), nil)
+
+	writer.WriteRow("a", "bb", "c")
+	writer.WriteRow("aaa", "b", "cc")
+	writer.Flush()
+
+	assert.Equal(t, "\x1b[44ma  \x1b[0m  bb  \x1b[44mc \x1b[0m\n"+
+		"\x1b[44maaa\x1b[0m  b   \x1b[44mcc\x1b[0m\n", buf.String())
+}
+
+func TestAlternatingRows(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDefaultColumn(Rigid{})
+	writer.SetHeaders("x", "y")
+	writer.SetHeaderStyle(nil)
+	writer.SetAlternatingRows(color.New(color.BgBlue), nil)
+
+	writer.WriteRow("a", "bb")
+	writer.WriteRow("aaa", "b")
+	writer.WriteRow("c", "d")
+	writer.Flush()
+
+	assert.Equal(t, "x    y\n"+
+		"\x1b[44ma  \x1b[0m  \x1b[44mbb\x1b[0m\n"+
+		"aaa  b\n"+
+		"\x1b[44mc  \x1b[0m  \x1b[44md \x1b[0m\n", buf.String())
+}
+
+func TestColumnStyle(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{Style: color.New(color.Faint)}, Rigid{})
+
+	writer.WriteRow("12:00", "started")
+	writer.AddRow().Cell("12:01").Style(color.New(color.Bold)).Cell("stopped").Done()
+	writer.Flush()
+
+	assert.Equal(t, "\x1b[2m12:00\x1b[22m  started\n"+
+		"\x1b[1m12:01\x1b[22m  stopped\n", buf.String())
+}
+
 func BenchmarkFlexwriter(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		var buf bytes.Buffer
@@ -337,3 +490,1026 @@ func TestOmit(t *testing.T) {
 
 	assertGolden(t, buf.String(), "omit.txt")
 }
+
+func TestEnforceWidth(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetColumns(
+		Rigid{},
+		Rigid{},
+	)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetEnforceWidth(true)
+	writer.SetClipMarker("…")
+
+	writer.WriteRow("hello", "wonderful world")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+-------+----------…\n"+
+		"| hello | wonderful…\n"+
+		"+-------+----------…\n", buf.String())
+}
+
+func TestOverflowIndicator(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(24)
+	writer.SetDefaultColumn(Rigid{})
+	writer.SetEnforceWidth(true)
+	writer.SetOverflowIndicator(" ▶ +%d")
+
+	writer.WriteRow("hello", "world", "how", "are", "you")
+	writer.WriteRow("oh", "hi", "mark")
+	writer.Flush()
+
+	// all columns fit, no indicator
+	writer.WriteRow("hello", "world")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"hello  world  how   ▶ +2\n"+
+		"oh     hi     mark  ▶ +2\n"+
+		"hello  world\n", buf.String())
+}
+
+func TestOverflowError(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(10)
+	writer.SetDefaultColumn(Rigid{})
+	writer.SetOverflowPolicy(OverflowError)
+
+	writer.WriteRow("hello", "world")
+	err := writer.Flush()
+	assert.ErrorIs(t, err, ErrOverflow)
+	assert.EqualError(t, err, "flexwriter: content does not fit in the target width: "+
+		"2 columns need a width of 12, but the target width is 10")
+	assert.Equal(t, "", buf.String())
+
+	// the rows are kept, so a retry with a larger width succeeds
+	writer.SetWidth(12)
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "hello  world\n", buf.String())
+
+	// not enforcing the width doesn't change the policy
+	writer.SetWidth(10)
+	writer.SetEnforceWidth(false)
+	writer.WriteRow("hello", "world")
+	assert.ErrorIs(t, writer.Flush(), ErrOverflow)
+}
+
+func TestMaxWidth(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(200)
+	writer.SetMaxWidth(20)
+	writer.SetColumns(Rigid{}, Flexed{})
+	writer.SetDecorator(GapDecorator{Gap: "|", Right: "|"})
+
+	writer.WriteRow("hello", "world")
+	writer.Flush()
+
+	// a smaller width is still honored
+	writer.SetWidth(16)
+	writer.WriteRow("hello", "world")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"hello|world        |\n"+
+		"hello|world    |\n", buf.String())
+}
+
+func TestFill(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(30)
+	writer.SetColumns(Rigid{}, Omit{}, Rigid{Align: Right}, Rigid{})
+	writer.SetDecorator(AsciiTableDecorator())
+
+	writer.SetFill(FillColumn{Index: 2})
+	writer.WriteRow("a", "omitted", "b", "c")
+	writer.WriteRow("ddd", "omitted", "eee", "fff")
+	writer.Flush()
+
+	writer.SetFill(FillGaps{})
+	writer.WriteRow("a", "omitted", "b", "c")
+	writer.WriteRow("ddd", "omitted", "eee", "fff")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+-----+----------------+-----+\n"+
+		"| a   |              b | c   |\n"+
+		"+-----+----------------+-----+\n"+
+		"| ddd |            eee | fff |\n"+
+		"+-----+----------------+-----+\n"+
+		"+-----------+----------+-----+\n"+
+		"| a         |   b      | c   |\n"+
+		"+-----------+----------+-----+\n"+
+		"| ddd       | eee      | fff |\n"+
+		"+-----------+----------+-----+\n", buf.String())
+}
+
+func TestCollapsibleGaps(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetCollapsibleGaps(true)
+
+	// fits without collapsing
+	writer.WriteRow("hello", "world")
+	writer.Flush()
+
+	// fits by collapsing one of the gaps
+	writer.WriteRow("hello", "world", "abcdefg")
+	writer.Flush()
+
+	// still doesn't fit with all gaps collapsed
+	writer.WriteRow("hello", "world", "how are you")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"hello  world\n"+
+		"hello world  abcdefg\n"+
+		"hello world how are\n"+
+		"            you\n", buf.String())
+}
+
+func TestGap(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(30)
+	writer.SetDefaultColumn(Rigid{})
+	writer.SetDecorator(GapDecorator{Gap: " ", Left: "|", Right: "|"})
+
+	// space-between
+	writer.SetGap(Gap{Grow: 1})
+	writer.WriteRow("hello", "world", "abc")
+	writer.Flush()
+
+	// space-around
+	writer.SetGap(Gap{Grow: 2, OuterGrow: 1})
+	writer.WriteRow("hello", "world", "abc")
+	writer.Flush()
+
+	// limited growth
+	writer.SetGap(Gap{Grow: 1, Min: 1, Max: 3})
+	writer.WriteRow("hello", "world", "abc")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"|hello        world       abc|\n"+
+		"|  hello      world     abc  |\n"+
+		"| hello    world    abc |\n", buf.String())
+}
+
+func TestWidthTieBreak(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(14)
+	writer.SetDefaultColumn(Flexed{})
+	writer.SetDecorator(GapDecorator{Gap: "|", Right: "|"})
+
+	for _, tieBreak := range []WidthTieBreak{TieBreakDefault, TieBreakFirst, TieBreakLast, TieBreakProportional} {
+		writer.SetWidthTieBreak(tieBreak)
+		writer.WriteRow("a", "b", "c")
+		writer.Flush()
+	}
+
+	assert.Equal(t, ""+
+		"a   |b   |c  |\n"+
+		"a   |b   |c  |\n"+
+		"a  |b   |c   |\n"+
+		"a   |b   |c  |\n", buf.String())
+}
+
+func TestWrapMode(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(24)
+	writer.SetClipMarker("…")
+	writer.SetColumns(
+		Shrinkable{Wrap: WrapChar},
+		Rigid{Wrap: WrapNone, Max: 8},
+		Shrinkable{Wrap: WrapNone},
+	)
+
+	writer.WriteRow("deadbeefcafe babe", "https://example.com", "no wrap")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"deadb  https:/…  no wrap\n"+
+		"eefca            \n"+
+		"fe ba            \n"+
+		"be               \n", buf.String())
+}
+
+func TestEmbeddedNewlines(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(24)
+	writer.SetDecorator(AsciiTableDecorator())
+
+	writer.WriteRow("panic: oops\n  at main()\n  at \x1b[1mrun()", "x")
+	writer.WriteRow("the end", "y")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+-------------+---+\n"+
+		"| panic: oops | x |\n"+
+		"|   at main() |   |\n"+
+		"|   at \x1b[1mrun()\x1b[0m  |   |\n"+
+		"+-------------+---+\n"+
+		"| the end     | y |\n"+
+		"+-------------+---+\n", buf.String())
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+
+	writer.WriteRow("a", "b")
+	out, err := writer.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, "a  b\n", out)
+
+	writer.WriteRow("a", "b")
+	writer.WriteRow("c", "d")
+	lines, err := writer.RenderLines()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a  b", "c  d"}, lines)
+
+	assert.Empty(t, buf.String())
+	assert.Equal(t, 0, writer.Stats().BufferedRows)
+}
+
+func TestEmptyRowPolicy(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+
+	writeRows := func() {
+		writer.WriteRow("a", "b")
+		writer.WriteRow()
+		writer.WriteRow("c", "d")
+		writer.Flush()
+	}
+
+	writer.SetEmptyRowPolicy(EmptyRowSkip)
+	writeRows()
+	writer.SetEmptyRowPolicy(EmptyRowSpacer)
+	writeRows()
+
+	assert.Equal(t, ""+
+		"+---+---+\n"+
+		"| a | b |\n"+
+		"+---+---+\n"+
+		"| c | d |\n"+
+		"+---+---+\n"+
+		"+---+---+\n"+
+		"| a | b |\n"+
+		"|   |   |\n"+
+		"+---+---+\n"+
+		"| c | d |\n"+
+		"+---+---+\n", buf.String())
+
+	// without any cell, spacers are empty lines
+	buf.Reset()
+	writer.SetDecorator(GapDecorator{Gap: "  "})
+	writer.WriteRow()
+	writer.WriteRow()
+	writer.Flush()
+	assert.Equal(t, "\n\n", buf.String())
+}
+
+func TestWriteRows(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+
+	writer.WriteRows([][]any{{"a", 1}, {"bb", 22}})
+	writer.WriteStringRows([][]string{{"ccc", "333"}})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a    1\n"+
+		"bb   22\n"+
+		"ccc  333\n", buf.String())
+}
+
+func TestWriteRowTagged(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	type status struct{ failed bool }
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDefaultColumn(Rigid{})
+	writer.SetRowFilter(func(tag any, cells []string) bool {
+		return cells[0] != "skipped"
+	})
+	writer.SetRowStyle(func(tag any) *color.Color {
+		if st, ok := tag.(status); ok && st.failed {
+			return color.New(color.FgRed)
+		}
+		return nil
+	})
+
+	writer.WriteRowTagged(status{failed: false}, "build", "ok")
+	writer.WriteRowTagged(status{failed: true}, "test", "ko")
+	writer.WriteRow("skipped", "a very long cell")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"build  ok\n"+
+		"\x1b[31mtest \x1b[0m  \x1b[31mko\x1b[0m\n", buf.String())
+}
+
+func TestHyphenation(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(16)
+	writer.SetColumns(Rigid{}, Shrinkable{})
+	writer.SetHyphenation(3)
+
+	writer.WriteRow("word:", "antidisestablishmentarianism")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"word:  antidise-\n"+
+		"       stablish-\n"+
+		"       mentaria-\n"+
+		"       nism\n", buf.String())
+}
+
+func TestColumnBreak(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetColumns(Rigid{}, Shrinkable{Break: func(r rune) bool { return r == '/' }})
+
+	writer.WriteRow("path:", "/usr/local/share/doc/flexwriter")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"path:  /usr/local/\n"+
+		"       share/doc/\n"+
+		"       flexwriter\n", buf.String())
+}
+
+func TestContinuationMarker(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetColumns(Rigid{}, Shrinkable{})
+	writer.SetContinuationMarker("↪ ")
+
+	writer.WriteRow("note:", "a cell that is too long for one line")
+	writer.WriteRow("", "another row")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"note:  a cell that\n"+
+		"       ↪ is too long\n"+
+		"       ↪ for one\n"+
+		"       ↪ line\n"+
+		"       another row\n", buf.String())
+}
+
+func TestBreakWords(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetColumns(Rigid{}, Shrinkable{BreakWords: true})
+
+	writer.WriteRow("commit:", "3f2a9c41d0b7e8f6a5c4")
+	writer.Flush()
+
+	// without BreakWords, the second column would overflow the width
+	writer.SetColumns(Rigid{}, Shrinkable{})
+	writer.WriteRow("commit:", "3f2a9c41d0b7e8f6a5c4")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"commit:  3f2a9c41d0b\n"+
+		"         7e8f6a5c4\n"+
+		"commit:  3f2a9c41d0b7e8f6a5c4\n", buf.String())
+}
+
+func TestHiddenKeys(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	// the first column is a timestamp used only to sort and filter the rows
+	writer.SetColumns(Omit{})
+	writer.SetRowSort(func(a, b []string) bool {
+		return a[0] < b[0]
+	})
+	writer.SetRowFilter(func(tag any, cells []string) bool {
+		return cells[0] >= "1700000000"
+	})
+
+	writer.WriteRow("1700000300", "c", "third")
+	writer.WriteRow("1600000000", "x", "filtered")
+	writer.WriteRow("1700000100", "a", "first")
+	writer.WriteSeparator()
+	writer.WriteRow("1700000200", "b", "second")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a  first\n"+
+		"c  third\n"+
+		"─────────\n"+
+		"b  second\n", buf.String())
+}
+
+func TestWriteRowIndent(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetColumns(Omit{}, Shrinkable{}, Rigid{Align: Right})
+
+	writer.WriteRowIndent(0, "x", "config", "")
+	writer.WriteRowIndent(1, "x", "server", "")
+	writer.WriteRowIndent(2, "x", "listen address", ":80")
+	writer.WriteRowIndent(2, "x", "timeout", "30s")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"config              \n"+
+		"  server            \n"+
+		"    listen       :80\n"+
+		"    address         \n"+
+		"    timeout      30s\n", buf.String())
+}
+
+func TestRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Omit{})
+	writer.SetRedactor(func(colIdx int, value string) string {
+		if colIdx == 2 {
+			return "***"
+		}
+		return value
+	})
+
+	writer.WriteRow("id", "user", "password")
+	fmt.Fprintln(writer, "1\talice\thunter2")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"user   ***\n"+
+		"alice  ***\n", buf.String())
+}
+
+func TestMasked(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Masked{}, Masked{Mask: "?"})
+
+	writer.WriteRow("alice", "hunter2", "a")
+	writer.WriteRow("bob", "correct horse battery staple", "")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"alice  ••••••  ?\n"+
+		"bob    ••••••  \n", buf.String())
+
+	// the content providers are masked too
+	buf.Reset()
+	writer.WriteRow("carol", func(width int) string { return "secret" }, "")
+	writer.Flush()
+
+	assert.Equal(t, "carol  ••••••  \n", buf.String())
+}
+
+func TestNormalization(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetNormalization(true)
+
+	writer.WriteRow("re\u0301sume\u0301", "cafe\u0301")
+	writer.Flush()
+
+	assert.Equal(t, "r\u00e9sum\u00e9  caf\u00e9\n", buf.String())
+}
+
+func TestStripANSI(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetStripANSI(true)
+	writer.SetHeaders("\x1b[1mname\x1b[0m", "url")
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteRow("\x1b[31mred\x1b[0m", "\x1b]8;;https://example.com\x1b\\example\x1b]8;;\x1b\\")
+	writer.WriteRow("plain", "x")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"name   url\n"+
+		"red    example\n"+
+		"plain  x\n", buf.String())
+}
+
+func TestResetStyles(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetResetStyles(true)
+
+	writer.WriteRow("\x1b[31mred", "plain")
+	writer.WriteRow("ok", "plain")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"\x1b[31mred\x1b[0m  plain\n"+
+		"ok   plain\n", buf.String())
+}
+
+func TestPadTrailing(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetPadTrailing(true)
+
+	writer.WriteRow("a", "short")
+	writer.WriteRow("b", "longer")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a  short \n"+
+		"b  longer\n", buf.String())
+}
+
+func TestTrimTrailing(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(GapDecorator{Gap: " | ", Right: "  "})
+	writer.SetTrimTrailing(true)
+
+	writer.WriteRow("a", "short")
+	writer.WriteRow("b", "longer")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a | short\n"+
+		"b | longer\n", buf.String())
+}
+
+func TestHighlight(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Omit{}, Shrinkable{})
+	writer.SetWidth(10)
+	writer.Highlight("bar baz", color.New(color.Bold))
+
+	writer.WriteRow("bar", "foo bar baz")
+	writer.Flush()
+
+	// the match is highlighted on both lines it is wrapped on
+	assert.Equal(t, ""+
+		"foo \x1b[1mbar\x1b[0m\n"+
+		"\x1b[1mbaz\x1b[22m\x1b[0m\n", buf.String())
+}
+
+func TestHighlightRegexp(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Omit{}, Rigid{Align: Right}, Rigid{})
+	writer.HighlightRegexp(regexp.MustCompile("[0-9]+"), color.New(color.BgYellow), 2)
+
+	writer.WriteRow("id 1", "id 2", "id 3")
+	writer.WriteRow("", "ids 20", "ids 30")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"  id 2  id \x1b[43m3\x1b[0m\n"+
+		"ids 20  ids \x1b[43m30\x1b[0m\n", buf.String())
+
+	// the columns are the written ones, even once mirrored
+	buf.Reset()
+	writer.SetColumns(Rigid{}, Rigid{})
+	writer.SetMirror(true)
+	writer.HighlightRegexp(regexp.MustCompile("[0-9]+"), color.New(color.BgYellow), 0)
+	writer.WriteRow("a1", "b2", "c3")
+	writer.Flush()
+
+	assert.Equal(t, "c3  b2  a\x1b[43m1\x1b[0m\n", buf.String())
+}
+
+func TestLinkTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{Align: Right, LinkTemplate: "https://tracker/issue/{cell}"}, Rigid{})
+
+	writer.WriteRow("A 1", "first")
+	writer.WriteRow("B12", "second")
+	writer.WriteRow("", "third")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"\x1b]8;;https://tracker/issue/A%201\x1b\\A 1\x1b]8;;\x1b\\  first\n"+
+		"\x1b]8;;https://tracker/issue/B12\x1b\\B12\x1b]8;;\x1b\\  second\n"+
+		"     third\n", buf.String())
+}
+
+func TestHyperlinkCells(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{Max: 6}, Rigid{})
+
+	link := "\x1b]8;;https://example.com/home\x1b\\"
+	writer.WriteRow(link+"go home"+"\x1b]8;;\x1b\\", "x")
+	writer.Flush()
+
+	// the link counts as its text only, and is continued on the next line
+	assert.Equal(t, ""+
+		link+"go\x1b]8;;\x1b\\      x\n"+
+		link+"home\x1b]8;;\x1b\\    \n", buf.String())
+}
+
+func TestRenderCells(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Flexed{})
+	writer.SetWidth(12)
+
+	bar := func(width int) string {
+		return strings.Repeat("#", width)
+	}
+	writer.WriteRow("cpu", bar)
+	writer.WriteRow("memory", func(width int) string { return bar(width / 2) })
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"cpu     ####\n"+
+		"memory  ##\n", buf.String())
+}
+
+func TestCopyConfigFrom(t *testing.T) {
+	var buf bytes.Buffer
+	base := New()
+	base.SetOutput(&buf)
+	base.SetDecorator(AsciiTableDecorator())
+	base.SetColumns(Rigid{Align: Right}, Rigid{})
+	base.WriteRow("not", "copied")
+
+	writer := New()
+	writer.CopyConfigFrom(base)
+	writer.SetColumn(1, Rigid{Align: Right})
+	writer.WriteRow("1", "a")
+	writer.WriteRow("22", "bb")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+----+----+\n"+
+		"|  1 |  a |\n"+
+		"+----+----+\n"+
+		"| 22 | bb |\n"+
+		"+----+----+\n", buf.String())
+
+	// the configuration of the base writer is unchanged
+	assert.Equal(t, []Column{Rigid{Align: Right}, Rigid{}}, base.Columns())
+	assert.Equal(t, 1, base.Stats().BufferedRows)
+}
+
+// cancelingWriter cancels a context once it has written a given number of
+// lines.
+type cancelingWriter struct {
+	bytes.Buffer
+	lines  int
+	cancel func()
+}
+
+func (w *cancelingWriter) Write(b []byte) (int, error) {
+	n, err := w.Buffer.Write(b)
+	if strings.Count(w.String(), "\n") >= w.lines {
+		w.cancel()
+	}
+	return n, err
+}
+
+func TestFlushContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &cancelingWriter{lines: 2, cancel: cancel}
+	writer := New()
+	writer.SetOutput(out)
+
+	writer.WriteRow("a")
+	writer.WriteRow("b")
+	writer.WriteRow("c")
+	assert.ErrorIs(t, writer.FlushContext(ctx), context.Canceled)
+	assert.Equal(t, "a\nb\n", out.String())
+	assert.Equal(t, 0, writer.Stats().BufferedRows)
+
+	// nothing is written with a cancelled context
+	writer.WriteRow("d")
+	assert.ErrorIs(t, writer.FlushContext(ctx), context.Canceled)
+	assert.Equal(t, "a\nb\n", out.String())
+	assert.Equal(t, 1, writer.Stats().BufferedRows)
+}
+
+func TestWriteRowSeq(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+
+	writer.WriteRow("result")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			writer.WriteRowSeq(uint64(i), i)
+		}(i)
+	}
+	wg.Wait()
+	writer.Flush()
+
+	assert.Equal(t, "result\n0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n", buf.String())
+}
+
+func TestWriteSeparator(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+
+	writer.WriteRow("a", "b")
+	writer.WriteSeparator()
+	writer.WriteSeparator() // consecutive separators are merged
+	fmt.Fprintln(writer, "c\td")
+	writer.WriteSeparator()
+	writer.Flush()
+
+	// table rows are already separated
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.WriteRow("a", "b")
+	writer.WriteSeparator()
+	writer.WriteRow("c", "d")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a  b\n"+
+		"────\n"+
+		"c  d\n"+
+		"────\n"+
+		"+---+---+\n"+
+		"| a | b |\n"+
+		"+---+---+\n"+
+		"| c | d |\n"+
+		"+---+---+\n", buf.String())
+}
+
+type ruleDecorator struct {
+	GapDecorator
+}
+
+func (ruleDecorator) RuleSeparator(rowIdx int, above, below RowKind, widths []int) string {
+	return fmt.Sprintf("=== %d %d/%d %v ===", rowIdx, above, below, widths)
+}
+
+func TestRuleDecorator(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(ruleDecorator{GapDecorator{Gap: " "}})
+	writer.SetHeaders("x", "y")
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteSeparator()
+	writer.WriteRow("a", "bb")
+	writer.WriteSeparator()
+	writer.WriteRow("c", "d")
+	writer.WriteSeparator()
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"x y\n"+
+		"=== 1 1/0 [1 2] ===\n"+
+		"a bb\n"+
+		"=== 2 0/0 [1 2] ===\n"+
+		"c d\n"+
+		"=== -1 0/-1 [1 2] ===\n", buf.String())
+}
+
+func TestFlushStats(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetColumns(Rigid{}, Shrinkable{}, Rigid{})
+	writer.SetOverflowPolicy(OverflowClip)
+
+	writer.WriteRow("hello", "world", "abc")
+	writer.WriteSeparator()
+	writer.WriteRow("hello", "how are you", "longer content")
+	stats, err := writer.FlushStats()
+
+	assert.NoError(t, err)
+	assert.Equal(t, FlushStats{
+		Rows:    2,
+		Lines:   5,
+		Widths:  []int{5, 5, 14},
+		Wrapped: 1,
+		Hidden:  1,
+	}, stats)
+}
+
+func TestStats(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(100)
+	writer.SetMaxWidth(60)
+	writer.SetColumns(Rigid{}, Omit{}, Rigid{})
+
+	writer.WriteRow("a", "b", "c")
+	writer.WriteSeparator()
+	fmt.Fprint(writer, "d\te\tf\ng\th")
+	assert.Equal(t, Stats{BufferedRows: 3, Columns: 3, Width: 60}, writer.Stats())
+
+	writer.Flush()
+	assert.Equal(t, Stats{BufferedRows: 0, Columns: 3, Width: 60}, writer.Stats())
+}
+
+func TestFixedWidths(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Rigid{})
+
+	writer.WriteRow("hello", "world")
+	writer.Flush()
+	widths := writer.WidthsSnapshot()
+	assert.Equal(t, []int{5, 5}, widths)
+
+	buf.Reset()
+	writer.SetFixedWidths(widths)
+	assert.True(t, writer.Stats().FixedWidths)
+	writer.WriteRow("a", "greetings", "x")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a      greet  x\n"+
+		"       ings   \n", buf.String())
+	assert.Equal(t, []int{5, 5, 1}, writer.WidthsSnapshot())
+}
+
+func TestLayout(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewForTesting(20)
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Flexed{})
+	writer.SetMirror(true)
+
+	writer.WriteRow("hello", "world")
+	widths, err := writer.Layout()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5, 13}, widths)
+
+	// nothing is written, the rows are kept as written
+	assert.Equal(t, "", buf.String())
+	writer.Flush()
+	assert.Equal(t, "        world  hello\n", buf.String())
+	assert.Equal(t, widths, writer.WidthsSnapshot())
+
+	// the buffered rows are left as they are, e.g. without the header row
+	writer.SetHeaders("greeting", "name")
+	writer.WriteRow("hi", "bob")
+	writer.Write([]byte("hey\talice\n"))
+	_, err = writer.Layout()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(writer.rows))
+	assert.Equal(t, BodyRow, writer.rows[0].kind)
+	assert.Equal(t, "hey\talice\n", string(writer.buffer))
+}
+
+func TestNewForTesting(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewForTesting(20)
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Flexed{})
+	writer.SetDecorator(ColorizeDecorator(
+		GapDecorator{Gap: "|", Right: "|"},
+		color.New(color.FgYellow),
+	))
+
+	writer.WriteRow("\x1b[1mhello\x1b[0m", "world")
+	writer.Flush()
+
+	assert.Equal(t, "hello|world        |\n", buf.String())
+}
+
+func TestTerminalWidthFunc(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewForTesting(80)
+	writer.SetTerminalWidthFunc(func(out io.Writer) (int, bool) {
+		return 132, out == &buf
+	})
+	writer.SetOutput(&buf)
+	assert.Equal(t, 132, writer.Stats().Width)
+
+	writer.SetWidth(80)
+	writer.SetOutput(io.Discard)
+	assert.Equal(t, 80, writer.Stats().Width)
+}
+
+func TestCaption(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewForTesting(80)
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+
+	writer.SetCaption(Caption{Text: "Title", Align: Center})
+	writer.WriteRow("hello", "world")
+	writer.Flush()
+
+	writer.SetCaption(Caption{Text: "a long caption is wrapped", Below: true, Align: Right})
+	writer.WriteRow("hello", "world")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"      Title\n"+
+		"+-------+-------+\n"+
+		"| hello | world |\n"+
+		"+-------+-------+\n"+
+		"+-------+-------+\n"+
+		"| hello | world |\n"+
+		"+-------+-------+\n"+
+		"a long caption is\n"+
+		"          wrapped\n", buf.String())
+}
+
+type kindDecorator struct {
+	debugDecorator
+}
+
+func (kindDecorator) RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string {
+	return fmt.Sprintf("--- %d %d/%d ---", rowIdx, above, below)
+}
+
+func (kindDecorator) ColumnSeparatorKind(rowIdx, colIdx int, kind RowKind) string {
+	return fmt.Sprintf(" %d/%d/%d ", rowIdx, colIdx, kind)
+}
+
+func TestKindDecorator(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewForTesting(80)
+	writer.SetOutput(&buf)
+	writer.SetDecorator(kindDecorator{})
+	writer.SetEmptyRowPolicy(EmptyRowSpacer)
+
+	writer.WriteRow("A")
+	writer.WriteRow()
+	writer.WriteRow("B")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"--- 0 -1/0 ---\n"+
+		" 1/0/0 A 1/-1/0 \n"+
+		" 2/0/4   2/-1/4 \n"+
+		"--- 2 4/0 ---\n"+
+		" -1/0/0 B -1/-1/0 \n"+
+		"--- -1 0/-1 ---\n", buf.String())
+}
@@ -0,0 +1,309 @@
+package flexwriter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Formatter converts the value of a cell to the text that is written; see
+// [Writer.SetFormatter].
+type Formatter func(value any) string
+
+// SetFormatter sets the formatter of the column of index col (as written,
+// including the omitted columns, starting at 0), that is called on each of its
+// cells when they are written instead of the default conversion with
+// fmt.Sprint. The formatters of this package leave the values that are not
+// numbers as they would be by default. A nil formatter restores the default.
+//
+// The formatter is called with the lock of the flex writer held, so it must
+// not call any of its methods.
+func (w *Writer) SetFormatter(col int, format Formatter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if format == nil {
+		delete(w.formatters, col)
+		return
+	}
+	if w.formatters == nil {
+		w.formatters = make(map[int]Formatter)
+	}
+	w.formatters[col] = format
+}
+
+// SetNilPlaceholder sets the text written for the nil cells, including the nil
+// pointers; the default is "<nil>", as written by fmt.Sprint.
+func (w *Writer) SetNilPlaceholder(placeholder string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.nilText = &placeholder
+}
+
+// SetErrorStyle sets the color of the cells whose value is an error, e.g. red,
+// unless the cell is given a style with [RowBuilder.Style]. The errors are
+// written with their Error method; a nil error is a nil cell, see
+// [Writer.SetNilPlaceholder]. A nil color, which is the default, leaves the
+// errors unstyled.
+func (w *Writer) SetErrorStyle(c *color.Color) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.errStyle = newStyle(c)
+}
+
+// maxDerefs is the maximum number of pointers that are dereferenced to get the
+// value of a cell, e.g. 2 for a **int.
+const maxDerefs = 8
+
+// deref dereferences the pointers to get the value of a cell, unless they have
+// their own String or Error method; it returns nil for a nil pointer.
+func deref(value any) any {
+	for i := 0; i < maxDerefs; i++ {
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Pointer {
+			return value
+		}
+		if v.IsNil() {
+			return nil
+		}
+		switch value.(type) {
+		case fmt.Stringer, error:
+			return value
+		}
+		value = v.Elem().Interface()
+	}
+	return value
+}
+
+// cellString converts the value of the cell of the given column to a string.
+func (w *Writer) cellString(col int, value any) string {
+	value = deref(value)
+	if value == nil && w.nilText != nil {
+		return *w.nilText
+	}
+	if format, ok := w.formatters[col]; ok {
+		return format(value)
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// toFloat converts a number, or a string that parses as one, to a float64.
+func toFloat(value any) (float64, bool) {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// siPrefixes are the SI prefixes of the successive powers of 1000.
+var siPrefixes = []string{"", "k", "M", "G", "T", "P", "E"}
+
+// SI returns a formatter that scales the numbers with SI prefixes, e.g.
+// 1234 as "1.2k" and 5600000 as "5.6M" with a precision of 1; precision is
+// the number of decimals of the scaled values. The numbers below 1000 are not
+// scaled, and integers are then written without decimals. SI is meant for
+// e.g. counts, in a column aligned on the [Right].
+func SI(precision int) Formatter {
+	if precision < 0 {
+		precision = 0
+	}
+	return func(value any) string {
+		f, ok := toFloat(value)
+		if !ok || math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Sprint(value)
+		}
+		abs := math.Abs(f)
+		if abs < 1000 {
+			if abs == math.Trunc(abs) {
+				return strconv.FormatFloat(f, 'f', 0, 64)
+			}
+			return strconv.FormatFloat(f, 'f', precision, 64)
+		}
+		var i int
+		for i < len(siPrefixes)-1 && abs >= 1000 {
+			abs /= 1000
+			i++
+		}
+		// e.g. 999.95k rounds up to 1000.0k, which is 1.0M
+		if rounded, _ := strconv.ParseFloat(strconv.FormatFloat(abs, 'f', precision, 64), 64); rounded >= 1000 && i < len(siPrefixes)-1 {
+			abs /= 1000
+			i++
+		}
+		s := strconv.FormatFloat(abs, 'f', precision, 64) + siPrefixes[i]
+		if f < 0 {
+			s = "-" + s
+		}
+		return s
+	}
+}
+
+// Scientific returns a formatter that writes the numbers in normalized
+// scientific notation, with the given number of decimals, e.g. 12345 as
+// "1.23e+04" with a precision of 2. The positive numbers are preceded by a
+// figure space (U+2007) where the negative ones have their minus sign, so that
+// in a column aligned on the [Left] or on the [Right], the mantissas and the
+// exponents of all the numbers are aligned.
+func Scientific(precision int) Formatter {
+	if precision < 0 {
+		precision = 0
+	}
+	return func(value any) string {
+		f, ok := toFloat(value)
+		if !ok || math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Sprint(value)
+		}
+		s := strconv.FormatFloat(f, 'e', precision, 64)
+		if !strings.HasPrefix(s, "-") {
+			s = "\u2007" + s
+		}
+		return s
+	}
+}
+
+// PercentFormat formats numbers as percentages, e.g. "42.0 %"; its Format
+// method is a [Formatter]. With a fixed precision and a column aligned on the
+// [Right], the decimal points of the percentages are aligned.
+type PercentFormat struct {
+	// Precision is the number of decimals of the percentages.
+	Precision int
+	// Ratio makes the values ratios, between 0 and 1, rather than percentages
+	// between 0 and 100.
+	Ratio bool
+	// Bar, if > 0, is the width of a bar, filled in proportion to the
+	// percentage, that is added after it.
+	Bar int
+}
+
+// Format formats a number as a percentage; see [PercentFormat].
+func (p PercentFormat) Format(value any) string {
+	f, ok := toFloat(value)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	if p.Ratio {
+		f *= 100
+	}
+	precision := p.Precision
+	if precision < 0 {
+		precision = 0
+	}
+	s := strconv.FormatFloat(f, 'f', precision, 64) + " %"
+	if p.Bar > 0 {
+		s += " " + bar(f/100, p.Bar)
+	}
+	return s
+}
+
+// barEighths are the block characters filled by eighths, from one to seven.
+var barEighths = []string{"▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// bar returns a bar of the given width, filled in proportion to ratio.
+func bar(ratio float64, width int) string {
+	if math.IsNaN(ratio) || ratio < 0 {
+		ratio = 0
+	} else if ratio > 1 {
+		ratio = 1
+	}
+	eighths := int(math.Round(ratio * float64(width) * 8))
+	full, part := eighths/8, eighths%8
+	s := strings.Repeat("█", full)
+	if part > 0 {
+		s += barEighths[part-1]
+		full++
+	}
+	// the empty part is not made of spaces, so that it isn't trimmed
+	return s + strings.Repeat("░", width-full)
+}
+
+// CurrencyFormat formats numbers as amounts of money, e.g. "$ 1,234.50", with
+// the separators of a given language; its Format method is a [Formatter]. The
+// amounts are rounded to the number of decimals of the currency.
+type CurrencyFormat struct {
+	// Currency is the currency of the amounts; by default, it is the currency
+	// of the region of Language, or the US dollar if there is none.
+	Currency currency.Unit
+	// Language sets the decimal and thousands separators; default is American
+	// English, e.g. "1,234.50".
+	Language language.Tag
+	// Code writes the ISO code of the currency, e.g. "EUR", instead of its
+	// symbol, e.g. "€".
+	Code bool
+	// After writes the currency after the amounts instead of before them.
+	After bool
+	// Parentheses writes the negative amounts between parentheses, as is
+	// usual in accounting, instead of with a minus sign. The positive amounts
+	// are then followed by a figure space (U+2007), so that the amounts stay
+	// aligned in a column aligned on the [Right].
+	Parentheses bool
+}
+
+// Format formats a number as an amount of money; see [CurrencyFormat].
+func (c CurrencyFormat) Format(value any) string {
+	f, ok := toFloat(value)
+	if !ok || math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Sprint(value)
+	}
+	lang := c.Language
+	if lang == language.Und {
+		lang = language.AmericanEnglish
+	}
+	unit := c.Currency
+	if unit == (currency.Unit{}) {
+		var conf language.Confidence
+		unit, conf = currency.FromTag(lang)
+		if conf == language.No {
+			unit = currency.USD
+		}
+	}
+	p := message.NewPrinter(lang)
+
+	scale, _ := currency.Standard.Rounding(unit)
+	// rounded half away from zero, rather than half to even by the printer
+	pow := math.Pow10(scale)
+	rounded := math.Round(math.Abs(f) * pow)
+	amount := p.Sprint(number.Decimal(rounded/pow, number.Scale(scale)))
+	symbol := unit.String()
+	if !c.Code {
+		symbol = p.Sprint(currency.Symbol(unit))
+	}
+	if c.After {
+		amount += " " + symbol
+	} else {
+		amount = symbol + " " + amount
+	}
+
+	// an amount rounded to zero is not negative
+	negative := f < 0 && rounded != 0
+	switch {
+	case c.Parentheses && negative:
+		return "(" + amount + ")"
+	case c.Parentheses:
+		return amount + " "
+	case negative:
+		return "-" + amount
+	}
+	return amount
+}
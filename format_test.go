@@ -0,0 +1,163 @@
+package flexwriter
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+func TestPointerCells(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+
+	name := "alice"
+	age := 42
+	agePtr := &age
+	var missing *int
+	writer.WriteRow(&name, &agePtr, missing, nil, big.NewInt(7))
+	writer.SetNilPlaceholder("-")
+	writer.WriteRow(&name, &agePtr, missing, nil, big.NewInt(7))
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"alice  42  <nil>  <nil>  7\n"+
+		"alice  42  -      -      7\n", buf.String())
+}
+
+func TestErrorStyle(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetErrorStyle(color.New(color.FgRed))
+	writer.SetNilPlaceholder("ok")
+
+	var noErr error
+	writer.WriteRow("a", noErr)
+	writer.WriteRow("b", errors.New("failed"))
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a  ok\n"+
+		"b  \x1b[31mfailed\x1b[0m\n", buf.String())
+}
+
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "called"
+}
+
+func TestLazyConversion(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetLazyConversion(true)
+
+	var calls int
+	value := 1
+	writer.WriteRow(countingStringer{&calls}, &value)
+	value = 2
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, 1, writer.Stats().BufferedRows)
+
+	writer.Flush()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "called  2\n", buf.String())
+
+	// the rows dropped by the tag filter are never converted
+	buf.Reset()
+	writer.SetRowTagFilter(func(tag any) bool { return tag != "skip" })
+	writer.WriteRowTagged("skip", countingStringer{&calls})
+	writer.WriteRowTagged("keep", 3)
+	writer.Flush()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "3\n", buf.String())
+}
+
+func TestSI(t *testing.T) {
+	format := SI(1)
+	assert.Equal(t, "0", format(0))
+	assert.Equal(t, "999", format(999))
+	assert.Equal(t, "1.5", format(1.5))
+	assert.Equal(t, "1.2k", format(1234))
+	assert.Equal(t, "-3.4M", format(int64(-3400000)))
+	assert.Equal(t, "5.6G", format(uint64(5600000000)))
+	assert.Equal(t, "1.0M", format(999999))
+	assert.Equal(t, "12.0k", format("12000"))
+	assert.Equal(t, "n/a", format("n/a"))
+	assert.Equal(t, "2k", SI(0)(1999.4))
+}
+
+func TestScientific(t *testing.T) {
+	format := Scientific(2)
+	assert.Equal(t, "\u20071.23e+04", format(12345))
+	assert.Equal(t, "-1.23e-04", format(-0.00012345))
+	assert.Equal(t, "\u20070.00e+00", format(0))
+	assert.Equal(t, "\u20071e+100", Scientific(0)(1e100))
+	assert.Equal(t, "n/a", format("n/a"))
+}
+
+func TestPercentFormat(t *testing.T) {
+	assert.Equal(t, "42 %", PercentFormat{}.Format(42))
+	assert.Equal(t, "42.0 %", PercentFormat{Precision: 1}.Format(42))
+	assert.Equal(t, "42.5 %", PercentFormat{Precision: 1, Ratio: true}.Format(0.425))
+	assert.Equal(t, "n/a", PercentFormat{}.Format("n/a"))
+	assert.Equal(t, "0 % ░░░░", PercentFormat{Bar: 4}.Format(0))
+	assert.Equal(t, "50 % ██░░", PercentFormat{Bar: 4}.Format(50))
+	assert.Equal(t, "60 % ██▍░", PercentFormat{Bar: 4}.Format(60))
+	assert.Equal(t, "150 % ████", PercentFormat{Bar: 4}.Format(150))
+}
+
+func TestCurrencyFormat(t *testing.T) {
+	assert.Equal(t, "$ 1,234.50", CurrencyFormat{}.Format(1234.5))
+	assert.Equal(t, "-$ 0.10", CurrencyFormat{}.Format(-0.1))
+	assert.Equal(t, "$ 0.00", CurrencyFormat{}.Format(-0.001))
+	assert.Equal(t, "n/a", CurrencyFormat{}.Format("n/a"))
+	assert.Equal(t, "1.234,50 €", CurrencyFormat{Language: language.German, After: true}.Format(1234.5))
+	assert.Equal(t, "JPY 1,235", CurrencyFormat{Currency: currency.JPY, Code: true}.Format(1234.5))
+	assert.Equal(t, "($ 12.00)", CurrencyFormat{Parentheses: true}.Format(-12))
+	assert.Equal(t, "$ 12.00\u2007", CurrencyFormat{Parentheses: true}.Format(12))
+	assert.Equal(t, "-৳ ১২.০০", CurrencyFormat{Language: language.Bengali}.Format(-12))
+	assert.Equal(t, "ریال ۰", CurrencyFormat{Language: language.Persian}.Format(-0.001))
+}
+
+func TestSetFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Omit{}, Rigid{}, Rigid{Align: Right})
+	writer.SetFormatter(2, SI(1))
+
+	writer.WriteRow(1, "requests", 123456)
+	writer.WriteRow(2, "errors", 12)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"requests  123.5k\n"+
+		"errors        12\n", buf.String())
+
+	// the figure space of the positive amounts is kept to align them
+	buf.Reset()
+	writer.SetFormatter(2, CurrencyFormat{Parentheses: true}.Format)
+	writer.WriteRow(1, "income", 1200)
+	writer.WriteRow(2, "expenses", -800)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"income    $ 1,200.00\u2007\n"+
+		"expenses   ($ 800.00)\n", buf.String())
+}
@@ -0,0 +1,108 @@
+package flexwriter
+
+// SetFrozenColumns sets columns, indexed as written (including the omitted
+// columns, starting at 0) as with [Writer.SetColumn], that are always shown
+// with the [OverflowClip] policy, like the frozen panes of a spreadsheet.
+// When the columns don't fit in the target width, the other columns are then
+// dropped entirely, starting with the right-most one, until the remaining
+// ones fit, and the width is shared among those again. The frozen columns
+// are also kept when columns are dropped because of their Priority (see
+// [Column]), whatever the policy. The dropped columns count as hidden
+// columns, see [Writer.SetOverflowIndicator]. Calling SetFrozenColumns
+// without any column restores the default clipping of the lines.
+func (w *Writer) SetFrozenColumns(cols ...int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.frozen = append([]int(nil), cols...)
+}
+
+// markFrozen marks the frozen visible columns of the buffered rows, before
+// columns are dropped; it returns a function that restores the configuration,
+// to be called once the rows are flushed.
+func (w *Writer) markFrozen() (restore func()) {
+	var n int
+	for _, r := range w.rows {
+		if len(r.cells) > n {
+			n = len(r.cells)
+		}
+	}
+
+	columns, fixedWidths := w.columns, w.fixedWidths
+	w.columns = make([]flexItem, n)
+	for ci := range w.columns {
+		w.columns[ci] = w.getColumnDefAt(columns, ci)
+		for _, col := range w.frozen {
+			if col == w.columns[ci].written {
+				w.columns[ci].frozen = true
+			}
+		}
+	}
+	// the fixed widths are copied as they are modified if columns are dropped
+	w.fixedWidths = append([]int(nil), fixedWidths...)
+
+	return func() {
+		w.columns, w.fixedWidths = columns, fixedWidths
+	}
+}
+
+// hasPriorities returns whether any visible column has a Priority.
+func (w *Writer) hasPriorities() bool {
+	if w.defaultCol.priority != 0 && !w.omitDefault {
+		return true
+	}
+	for _, col := range w.columns {
+		if col.priority != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dropColumns drops the columns that are not frozen from the buffered rows,
+// the ones of the lowest Priority first and the right-most first among them,
+// until the layout fits in the target width; at least one column is kept.
+func (w *Writer) dropColumns(l layout) (layout, error) {
+	var dropped int
+	// the rows are copied so that they are kept as written if the output
+	// fails, see WriteError
+	w.rows = append([]row(nil), w.rows...)
+	for totalWidth(l.deco, l.outerWidths()) > w.targetWidth() && len(l.widths) > 1 {
+		drop := -1
+		for ci := len(l.widths) - 1; ci >= 0; ci-- {
+			col := w.getColumnDef(ci)
+			if !col.frozen && (drop == -1 || col.priority < w.getColumnDef(drop).priority) {
+				drop = ci
+			}
+		}
+		if drop == -1 {
+			break
+		}
+
+		for i, r := range w.rows {
+			r = dropSpan(r, drop)
+			w.rows[i] = r
+			if drop < len(r.cells) {
+				w.rows[i].cells = append(r.cells[:drop:drop], r.cells[drop+1:]...)
+			}
+			if drop < len(r.opts) {
+				w.rows[i].opts = append(r.opts[:drop:drop], r.opts[drop+1:]...)
+			}
+		}
+		if drop < len(w.columns) {
+			w.columns = append(w.columns[:drop], w.columns[drop+1:]...)
+		}
+		if drop < len(w.fixedWidths) {
+			w.fixedWidths = append(w.fixedWidths[:drop], w.fixedWidths[drop+1:]...)
+		}
+		dropped++
+
+		var err error
+		l, err = w.computeLayout()
+		if err != nil {
+			return l, err
+		}
+	}
+	l.dropped = dropped
+	return l, nil
+}
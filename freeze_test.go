@@ -0,0 +1,70 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrozenColumns(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetOverflowPolicy(OverflowClip)
+	writer.SetOverflowIndicator(" +%d")
+	writer.SetColumns(Rigid{}, Rigid{}, Rigid{}, Rigid{})
+	writer.SetFrozenColumns(0, 3)
+	writer.SetWidth(38)
+
+	writer.WriteRow("id", "description", "owner", "status of the job")
+	writer.WriteRow("1", "first job", "alice", "done")
+	stats, err := writer.FlushStats()
+	assert.NoError(t, err)
+
+	assert.Equal(t, ""+
+		"id  description  status of the job  +1\n"+
+		"1   first job    done               +1\n", buf.String())
+	assert.Equal(t, 1, stats.Hidden)
+
+	// the columns that fit are laid out as usual
+	buf.Reset()
+	writer.SetWidth(80)
+	writer.WriteRow("1", "first job", "alice", "done")
+	writer.Flush()
+	assert.Equal(t, "1  first job  alice  done\n", buf.String())
+}
+
+func TestColumnPriorities(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{Priority: 2}, Rigid{}, Rigid{Priority: 1}, Rigid{})
+	writer.SetWidth(24)
+
+	writer.WriteRow("name", "description", "status", "owner")
+	writer.WriteRow("job", "first job", "done", "alice")
+	stats, err := writer.FlushStats()
+	assert.NoError(t, err)
+
+	// the columns of priority 0 are dropped, the right-most first
+	assert.Equal(t, ""+
+		"name  status\n"+
+		"job   done\n", buf.String())
+	assert.Equal(t, 2, stats.Hidden)
+
+	// the dropped columns are signalled by the indicator, even if the lines
+	// are not clipped
+	buf.Reset()
+	writer.SetOverflowIndicator(" +%d")
+	writer.WriteRow("job", "first job", "done", "alice")
+	writer.Flush()
+	assert.Equal(t, "job  first job  done  +1\n", buf.String())
+
+	// the frozen columns are kept
+	buf.Reset()
+	writer.SetFrozenColumns(3)
+	writer.WriteRow("job", "first job", "done", "alice")
+	writer.Flush()
+	assert.Equal(t, "job  done  alice      +1\n", buf.String())
+}
@@ -35,6 +35,13 @@ func transform[F any, T any](s []F, f func(F) T) []T {
 	return out
 }
 
+// reverse reverses the order of the elements of s, in place.
+func reverse[T any](s []T) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
 func max(s []int) int {
 	if len(s) == 0 {
 		return 0
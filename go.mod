@@ -8,6 +8,7 @@ require (
 	github.com/mattn/go-runewidth v0.0.16
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/term v0.28.0
+	golang.org/x/text v0.21.0
 )
 
 require (
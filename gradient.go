@@ -0,0 +1,145 @@
+package flexwriter
+
+import (
+	"fmt"
+	"strings"
+
+	text "github.com/MichaelMure/go-term-text"
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// RGB is a 24-bit color.
+type RGB struct {
+	R, G, B uint8
+}
+
+// Gradient is a color gradient for the [GradientDecorator].
+type Gradient struct {
+	// From is the color of the left border, or the top border if Vertical.
+	From RGB
+	// To is the color of the right border, or the bottom border if Vertical.
+	To RGB
+	// Vertical makes the gradient go from the top to the bottom, instead of
+	// from the left to the right.
+	Vertical bool
+	// Rows is the number of rows over which a vertical gradient goes from
+	// From to To; the rows below it, as well as the bottom border, have the
+	// To color. If 0, it defaults to 10.
+	Rows int
+}
+
+// gradientDecorator wraps a decorator to color it with a gradient.
+type gradientDecorator struct {
+	parent   Decorator
+	gradient Gradient
+	// widths are the widths given to the last call to RowSeparator, which
+	// happens before any row is written, to position the column separators
+	widths []int
+}
+
+// GradientDecorator wraps a decorator to color it with a 24-bit color
+// gradient, e.g. for banner-like tables. Like with [ColorizeDecorator], the
+// colors are not used if the fatih/color package disables them.
+//
+// As the decorator keeps track of the widths of the columns while the rows are
+// written, it must not be used by several writers at the same time.
+func GradientDecorator(parent Decorator, gradient Gradient) Decorator {
+	if gradient.Rows <= 0 {
+		gradient.Rows = 10
+	}
+	return &gradientDecorator{parent: parent, gradient: gradient}
+}
+
+func (d *gradientDecorator) RowSeparator(rowIdx int, widths []int) string {
+	d.widths = widths
+	return d.paint(d.parent.RowSeparator(rowIdx, widths), rowIdx, 0)
+}
+
+func (d *gradientDecorator) ColumnSeparator(rowIdx, colIdx int) string {
+	return d.paint(d.parent.ColumnSeparator(rowIdx, colIdx), rowIdx, d.columnSeparatorPos(colIdx))
+}
+
+func (d *gradientDecorator) RowSeparatorKind(rowIdx int, above, below RowKind, widths []int) string {
+	d.widths = widths
+	return d.paint(rowSeparator(d.parent, rowIdx, above, below, widths), rowIdx, 0)
+}
+
+func (d *gradientDecorator) RowSeparatorSpan(rowIdx int, above, below RowKind, widths []int, joinedAbove, joinedBelow []bool) string {
+	d.widths = widths
+	return d.paint(rowSeparatorSpan(d.parent, rowIdx, above, below, widths, joinedAbove, joinedBelow), rowIdx, 0)
+}
+
+func (d *gradientDecorator) RuleSeparator(rowIdx int, above, below RowKind, widths []int) string {
+	d.widths = widths
+	return d.paint(ruleSeparator(d.parent, rowIdx, above, below, widths), rowIdx, 0)
+}
+
+func (d *gradientDecorator) ColumnSeparatorKind(rowIdx, colIdx int, kind RowKind) string {
+	return d.paint(columnSeparator(d.parent, rowIdx, colIdx, kind), rowIdx, d.columnSeparatorPos(colIdx))
+}
+
+// columnSeparatorPos returns the horizontal position of a column separator.
+func (d *gradientDecorator) columnSeparatorPos(colIdx int) int {
+	switch {
+	case colIdx == 0:
+		return 0
+	case colIdx == -1:
+		return d.totalWidth() - textutil.Width(d.parent.ColumnSeparator(0, -1))
+	}
+	pos := textutil.Width(d.parent.ColumnSeparator(0, 0))
+	for i := 0; i < colIdx && i < len(d.widths); i++ {
+		if i > 0 {
+			pos += textutil.Width(d.parent.ColumnSeparator(0, i))
+		}
+		pos += d.widths[i]
+	}
+	return pos
+}
+
+func (d *gradientDecorator) totalWidth() int {
+	return totalWidth(d.parent, d.widths)
+}
+
+// paint colors the separator of the given row, that starts at the given
+// horizontal position.
+func (d *gradientDecorator) paint(sep string, rowIdx, pos int) string {
+	if sep == "" {
+		return sep
+	}
+	sep, _ = text.ExtractTermEscapes(sep)
+
+	if d.gradient.Vertical {
+		t := 1.0
+		if rowIdx >= 0 && rowIdx < d.gradient.Rows {
+			t = float64(rowIdx) / float64(d.gradient.Rows)
+		}
+		return d.escape(t) + sep + "\x1b[0m"
+	}
+
+	var sb strings.Builder
+	last := d.totalWidth() - 1
+	for _, r := range sep {
+		t := 0.0
+		if last > 0 {
+			t = float64(pos) / float64(last)
+		}
+		sb.WriteString(d.escape(t))
+		sb.WriteRune(r)
+		pos += textutil.Width(string(r))
+	}
+	sb.WriteString("\x1b[0m")
+	return sb.String()
+}
+
+// escape returns the escape sequence of the color at the position t of the
+// gradient, between 0 and 1.
+func (d *gradientDecorator) escape(t float64) string {
+	if t > 1 {
+		t = 1
+	}
+	lerp := func(from, to uint8) int {
+		return int(float64(from) + (float64(to)-float64(from))*t + 0.5)
+	}
+	from, to := d.gradient.From, d.gradient.To
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", lerp(from.R, to.R), lerp(from.G, to.G), lerp(from.B, to.B))
+}
@@ -0,0 +1,240 @@
+package flexwriter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/hchargois/flexwriter/textutil"
+	"golang.org/x/text/unicode/norm"
+)
+
+// SetHeaders sets the cells of a header row, written before the other rows at
+// each flush, unless there are none. The header row is of kind [HeaderRow],
+// so that the decorator can e.g. draw a distinct separator below it (see
+// [TableDecorator]); it is neither filtered nor sorted with the other rows,
+// and it is repeated on each page (see [Writer.SetPageSize]). The cells are
+// converted with [fmt.Sprint] if they are not strings, but the formatters,
+// the redactor and the masks of the columns are not applied. Calling
+// SetHeaders without cells removes the header row.
+func (w *Writer) SetHeaders(cells ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.headers = append([]any(nil), cells...)
+	if len(cells) == 0 {
+		w.headers = nil
+	}
+}
+
+// WriteFooter writes a footer row, e.g. of totals, like [Writer.WriteRow]. The
+// kind of the row is [FooterRow], so that the decorator can e.g. draw a
+// distinct separator above it (see [TableDecorator]); it is neither filtered
+// nor sorted with the other rows, and the footer rows are written after all
+// the other rows of the flush, in the order they were written.
+func (w *Writer) WriteFooter(cells ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeRow(cells...)
+	w.rows[len(w.rows)-1].kind = FooterRow
+	w.streamRows()
+}
+
+// SetHeaderStyle sets the color of the cells of the header rows set with
+// [Writer.SetHeaders] and [Writer.SetColumnGroups], regardless of the styles
+// of the cells, so that they stand out even without a table decorator. The
+// default is bold; a nil color leaves the header cells unstyled.
+func (w *Writer) SetHeaderStyle(c *color.Color) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.headerStyle = newStyle(c)
+}
+
+// SetHeaderBreakWords sets whether the cells of the header row may be broken
+// anywhere, like the cells of the columns with BreakWords, so that long labels
+// don't widen narrow numeric columns. By default, the header cells are wrapped
+// like the other cells of their column.
+//
+// Deprecated: use [Writer.SetHeaderWrap] with [WrapChar] instead, which also
+// fills the lines of the header cells entirely.
+func (w *Writer) SetHeaderBreakWords(breakWords bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.headerBreak = breakWords
+}
+
+// SetHeaderWrap sets how the cells of the header row are wrapped, instead of
+// the wrap mode of their columns, e.g. [WrapChar] so that long labels don't
+// widen narrow numeric columns. By default, the header cells are wrapped like
+// the other cells of their column.
+func (w *Writer) SetHeaderWrap(mode WrapMode) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.headerWrap = &mode
+}
+
+// SetHeaderMaxLines limits the cells of the header row to n lines once
+// wrapped; the last line of the cells that are cut ends with the clip marker
+// (see [Writer.SetClipMarker]). A limit of 0, the default, means no limit.
+func (w *Writer) SetHeaderMaxLines(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.headerLines = n
+}
+
+// headerDef returns the definition of a column for the cells of the rows of
+// the given kind, i.e. with the wrap mode of the headers for the header row.
+func (w *Writer) headerDef(def flexItem, kind RowKind) flexItem {
+	if kind == HeaderRow && w.headerWrap != nil {
+		def.wrap = *w.headerWrap
+	}
+	return def
+}
+
+// clipHeader cuts the wrapped lines of a cell of a header row to the maximum
+// number of lines of the headers, if any.
+func (w *Writer) clipHeader(r row, lines []string, width int) []string {
+	if r.kind != HeaderRow || w.headerLines <= 0 || len(lines) <= w.headerLines {
+		return lines
+	}
+	n := w.headerLines
+	// the next line didn't fit on the last one, so their concatenation is
+	// always cut, and ends with the marker
+	next := strings.TrimPrefix(lines[n], w.contMarker)
+	lines[n-1] = textutil.Truncate(lines[n-1]+" "+next, width, w.clipMarker)
+	return lines[:n]
+}
+
+// ColumnGroup is a group of adjacent columns with a shared header, see
+// [Writer.SetColumnGroups].
+type ColumnGroup struct {
+	// Header is the header of the group, written above the headers of the
+	// columns.
+	Header string
+	// From and To are the indexes of the first column of the group (included)
+	// and of the last one (excluded), indexed as written (including the
+	// omitted columns, starting at 0) as with [Writer.SetColumnRange].
+	From, To int
+}
+
+// SetColumnGroups sets groups of adjacent columns whose headers are written
+// in an extra header row, above the one set with [Writer.SetHeaders]: the
+// header of each group is centered over the visible columns of the group, and
+// the decorator draws the separators around it accordingly if it is a
+// [SpanDecorator], as the table decorators are. The header of a group is
+// wrapped if it is wider than its columns. The groups must not overlap, and
+// should not be split by the Order of the columns. The row of the group
+// headers is left out of the exports, e.g. [Writer.FlushJSON]. Calling
+// SetColumnGroups without groups removes the row of the group headers.
+func (w *Writer) SetColumnGroups(groups ...ColumnGroup) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.groups = append([]ColumnGroup(nil), groups...)
+	if len(groups) == 0 {
+		w.groups = nil
+	}
+}
+
+// addHeaders inserts the header rows before the buffered rows, if there are
+// header rows and some rows.
+func (w *Writer) addHeaders() {
+	if w.headers == nil && w.groups == nil {
+		return
+	}
+	var hasRows bool
+	var n int
+	for _, r := range w.rows {
+		if !r.rule {
+			hasRows = true
+		}
+		if len(r.cells) > n {
+			n = len(r.cells)
+		}
+	}
+	if !hasRows {
+		return
+	}
+
+	var headers []row
+	if w.groups != nil {
+		headers = append(headers, w.groupRow(n))
+	}
+	if w.headers != nil {
+		headers = append(headers, w.headerRow())
+	}
+	w.rows = append(headers, w.rows...)
+}
+
+// headerRow returns the header row set with SetHeaders.
+func (w *Writer) headerRow() row {
+	all := make([]string, len(w.headers))
+	for i, cell := range w.headers {
+		if s, ok := cell.(string); ok {
+			all[i] = s
+		} else {
+			all[i] = fmt.Sprint(cell)
+		}
+		if w.normalize {
+			all[i] = norm.NFC.String(all[i])
+		}
+		if w.stripANSI {
+			all[i] = textutil.StripEscapes(all[i])
+		}
+	}
+	var cells []string
+	var opts []cellOpts
+	for i := range all {
+		if !w.isOmitted(i) {
+			cells = append(cells, all[i])
+			opts = append(opts, cellOpts{style: w.headerStyle})
+		}
+	}
+	return row{cells: cells, all: all, opts: opts, kind: HeaderRow}
+}
+
+// groupRow returns the row of the headers of the column groups, for n visible
+// columns; each header spans the visible columns of its group.
+func (w *Writer) groupRow(n int) row {
+	group := func(colIdx int) int {
+		written := w.writtenIndex(colIdx)
+		for g, group := range w.groups {
+			if written >= group.From && written < group.To {
+				return g
+			}
+		}
+		return -1
+	}
+
+	cells := make([]string, n)
+	spans := make([]int, n)
+	opts := make([]cellOpts, n)
+	center := Center
+	start := -1
+	for ci := 0; ci < n; ci++ {
+		g := group(ci)
+		if g == -1 {
+			start = -1
+			continue
+		}
+		if start != -1 && group(start) == g {
+			spans[start]++
+			continue
+		}
+		start = ci
+		cells[ci], spans[ci] = w.groups[g].Header, 1
+		opts[ci].align, opts[ci].style = &center, w.headerStyle
+		if w.normalize {
+			cells[ci] = norm.NFC.String(cells[ci])
+		}
+		if w.stripANSI {
+			cells[ci] = textutil.StripEscapes(cells[ci])
+		}
+	}
+	return row{cells: cells, all: cells, opts: opts, spans: spans, kind: HeaderRow, group: true}
+}
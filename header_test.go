@@ -0,0 +1,206 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Omit{}, Rigid{Align: Right})
+	writer.SetHeaders("name", "id", "qty")
+	writer.SetHeaderStyle(nil)
+	writer.SetRowSort(func(a, b []string) bool { return a[0] < b[0] })
+
+	writer.WriteRow("pear", 1, 3)
+	writer.WriteRow("apple", 2, 12)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+-------+-----+\n"+
+		"| name  | qty |\n"+
+		"+=======+=====+\n"+
+		"| apple |  12 |\n"+
+		"+-------+-----+\n"+
+		"| pear  |   3 |\n"+
+		"+-------+-----+\n", buf.String())
+}
+
+func TestHeaderStyle(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	type item struct {
+		Name string `flex:"name"`
+		Qty  int    `flex:"qty"`
+	}
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Rigid{})
+
+	writer.WriteStructsWithHeader([]item{{"apple", 12}})
+	writer.Flush()
+
+	// bold by default
+	assert.Equal(t, ""+
+		"\x1b[1mname \x1b[22m  \x1b[1mqty\x1b[22m\n"+
+		"apple  12\n", buf.String())
+
+	buf.Reset()
+	writer.SetHeaderStyle(color.New(color.Underline))
+	writer.WriteStructsWithHeader([]item{{"apple", 12}})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"\x1b[4mname \x1b[24m  \x1b[4mqty\x1b[24m\n"+
+		"apple  12\n", buf.String())
+
+	buf.Reset()
+	writer.SetHeaderStyle(nil)
+	writer.WriteStructsWithHeader([]item{{"apple", 12}})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"name   qty\n"+
+		"apple  12\n", buf.String())
+}
+
+func TestHeaderWrap(t *testing.T) {
+	type item struct {
+		Fruit string `flex:"fruit"`
+		Qty   int    `flex:"quantity in stock"`
+	}
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(14)
+	writer.SetHeaderStyle(nil)
+	writer.SetColumns(Shrinkable{}, Shrinkable{})
+
+	writer.WriteStructsWithHeader([]item{{"apple", 12}})
+	writer.Flush()
+
+	// headers are wrapped like the other cells by default
+	assert.Equal(t, ""+
+		"fruit  quantity\n"+
+		"       in stock\n"+
+		"apple  12\n", buf.String())
+
+	buf.Reset()
+	writer.SetHeaderBreakWords(true)
+	writer.WriteStructsWithHeader([]item{{"apple", 12}})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"fruit  quantit\n"+
+		"       y in\n"+
+		"       stock\n"+
+		"apple  12\n", buf.String())
+
+	buf.Reset()
+	writer.SetHeaderBreakWords(false)
+	writer.SetHeaderWrap(WrapChar)
+	writer.WriteStructsWithHeader([]item{{"apple", 12}})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"fruit  quantit\n"+
+		"       y in st\n"+
+		"       ock\n"+
+		"apple  12\n", buf.String())
+
+	buf.Reset()
+	writer.SetHeaderWrap(WrapWord)
+	writer.SetHeaderMaxLines(1)
+	writer.SetClipMarker("…")
+	writer.WriteStructsWithHeader([]item{{"apple", 12}})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"fruit  quantit…\n"+
+		"apple  12\n", buf.String())
+}
+
+func TestColumnGroups(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(BoxDrawingTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{Align: Right}, Rigid{Align: Right}, Rigid{})
+	writer.SetHeaders("disk", "read", "write", "model")
+	writer.SetColumnGroups(ColumnGroup{Header: "Throughput", From: 1, To: 3})
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteRow("sda", "12 MB/s", "3 MB/s", "Samsung SSD")
+	writer.WriteRow("sdb", "0 MB/s", "140 MB/s", "WD Blue")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"┌──────┬────────────────────┬─────────────┐\n"+
+		"│      │     Throughput     │             │\n"+
+		"├──────┼─────────┬──────────┼─────────────┤\n"+
+		"│ disk │    read │    write │ model       │\n"+
+		"╞══════╪═════════╪══════════╪═════════════╡\n"+
+		"│ sda  │ 12 MB/s │   3 MB/s │ Samsung SSD │\n"+
+		"├──────┼─────────┼──────────┼─────────────┤\n"+
+		"│ sdb  │  0 MB/s │ 140 MB/s │ WD Blue     │\n"+
+		"└──────┴─────────┴──────────┴─────────────┘\n", buf.String())
+}
+
+func TestColumnGroupsDropped(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{Priority: -1}, Rigid{}, Omit{}, Rigid{Priority: 1})
+	writer.SetColumnGroups(ColumnGroup{Header: "group", From: 1, To: 5})
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteRow("a", "dropped column", "b", "omitted", "c")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+---+-------+\n"+
+		"|   | group |\n"+
+		"+===+===+===+\n"+
+		"| a | b | c |\n"+
+		"+---+---+---+\n", buf.String())
+}
+
+func TestWriteFooter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{Align: Right})
+	writer.SetHeaders("name", "qty")
+	writer.SetHeaderStyle(nil)
+	writer.SetRowSort(func(a, b []string) bool { return a[0] < b[0] })
+
+	writer.WriteFooter("total", 15)
+	writer.WriteRow("pear", 3)
+	writer.WriteRow("apple", 12)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+-------+-----+\n"+
+		"| name  | qty |\n"+
+		"+=======+=====+\n"+
+		"| apple |  12 |\n"+
+		"+-------+-----+\n"+
+		"| pear  |   3 |\n"+
+		"+=======+=====+\n"+
+		"| total |  15 |\n"+
+		"+-------+-----+\n", buf.String())
+}
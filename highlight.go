@@ -0,0 +1,78 @@
+package flexwriter
+
+import (
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// highlight is a pattern highlighted in the cells.
+type highlight struct {
+	re      *regexp.Regexp
+	style   style
+	columns []int // indexes of the highlighted columns, all if empty
+}
+
+// Highlight highlights with the given color all the occurrences of substr in
+// the cells that are flushed afterwards, including the occurrences that end
+// up wrapped over several lines. The search ignores the escape sequences of
+// the cells, but is otherwise an exact match. Calling Highlight with an empty
+// substr removes the highlighting.
+//
+// This is meant for e.g. interactive "filter as you type" tools, that redraw
+// their output each time the search term changes.
+func (w *Writer) Highlight(substr string, c *color.Color) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if substr == "" {
+		w.highlight = nil
+		return
+	}
+	w.highlight = &highlight{re: regexp.MustCompile(regexp.QuoteMeta(substr)), style: newStyle(c)}
+}
+
+// HighlightRegexp is like [Writer.Highlight], but highlights the matches of a
+// regular expression, and only in the given columns if any are given; as with
+// [Writer.SetColumn], the columns are indexed as written, starting at 0.
+// Only the matched text is highlighted, not the padding of the cells nor
+// their alignment, so a background color (e.g. color.BgYellow) can be used to
+// show the matches as a search tool would. Calling HighlightRegexp with a nil
+// re removes the highlighting.
+func (w *Writer) HighlightRegexp(re *regexp.Regexp, c *color.Color, columns ...int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if re == nil {
+		w.highlight = nil
+		return
+	}
+	w.highlight = &highlight{re: re, style: newStyle(c), columns: append([]int(nil), columns...)}
+}
+
+// highlights returns whether the column of the given index, as laid out when
+// flushing, is highlighted.
+func (h *highlight) highlights(w *Writer, colIdx int) bool {
+	if len(h.columns) == 0 {
+		return true
+	}
+	written := w.getColumnDef(colIdx).written
+	for _, col := range h.columns {
+		if col == written {
+			return true
+		}
+	}
+	return false
+}
+
+// highlightCell highlights the matches of the highlighted pattern in a cell.
+func (w *Writer) highlightCell(r row, colIdx int, cell string) string {
+	h := w.highlight
+	if h == nil || h.style.isZero() || w.noColors || !h.highlights(w, colIdx) {
+		return cell
+	}
+	// the styles of the column and of the cell are restarted after a match
+	restart := w.tint(r, colIdx).in + w.cellStyle(r, colIdx).in
+	return textutil.Highlight(cell, h.re, h.style.in, h.style.out+restart)
+}
@@ -0,0 +1,431 @@
+package flexwriter
+
+import (
+	"fmt"
+
+	"github.com/hchargois/flexwriter/flex"
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// Gap configures how the gaps between the columns take part in the flex
+// sizing. The width of a gap is the width of the corresponding column
+// separator of the decorator, plus some extra spaces that can grow like any
+// other flex item when there is some space left by the columns.
+//
+// For example, with rigid columns, a Gap{Grow: 1} spreads the columns over the
+// whole output width like a "justify-content: space-between" in CSS; and a
+// Gap{Grow: 2, OuterGrow: 1} is similar to "justify-content: space-around".
+type Gap struct {
+	// Grow is the grow weight of each gap between two columns.
+	Grow int
+	// OuterGrow is the grow weight of the gaps on the left of the first column
+	// and on the right of the last column.
+	OuterGrow int
+	// Min is the minimum number of extra spaces in each gap, including the
+	// outer ones.
+	Min int
+	// Max is the maximum number of extra spaces in each gap. If Max is 0, then
+	// there is no maximum.
+	Max int
+}
+
+// SetGap sets how the gaps between the columns take part in the flex sizing.
+// By default, the gaps don't grow and are as wide as the column separators of
+// the decorator.
+func (w *Writer) SetGap(gap Gap) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.gap = gap
+}
+
+// WidthTieBreak chooses which columns get the spare cells left over when the
+// free space can't be divided exactly between the columns, e.g. when 10 cells
+// are shared between 3 columns of equal weights. Each column that grows or
+// shrinks first gets the integer part of its exact share of the free space;
+// the spare cells, fewer than those columns, are then given one by one to the
+// columns chosen by the tie break, at most one cell each. When the columns
+// shrink, a spare cell is a cell that a column is shrunk less. The choice
+// only depends on the configuration and the content of the columns, so that
+// the same table is always laid out the same way, e.g. when refreshing a live
+// output.
+type WidthTieBreak int
+
+const (
+	// TieBreakDefault gives the spare cells to the columns whose exact share
+	// has the largest fractional part (largest remainder method), the first
+	// ones in case of equality, so that columns of equal weights get widths
+	// that differ by at most 1, the wider ones first.
+	TieBreakDefault WidthTieBreak = iota
+	// TieBreakFirst gives the spare cells to the first columns that grow or
+	// shrink, whatever the fractional parts of their shares: e.g. 10 cells
+	// shared between 3 columns of equal weights give them 4, 3 and 3 cells.
+	// Only the spare cells are given by position, not the shares themselves.
+	TieBreakFirst
+	// TieBreakLast gives the spare cells to the last columns that grow or
+	// shrink, whatever the fractional parts of their shares: e.g. 3, 3 and 4
+	// cells for 10 cells shared between 3 columns of equal weights.
+	TieBreakLast
+	// TieBreakWidest gives the spare cells to the columns that are the widest
+	// once they got the integer part of their shares, the first ones in case
+	// of equality.
+	TieBreakWidest
+	// TieBreakProportional is another name of TieBreakDefault, for the
+	// largest remainder method.
+	TieBreakProportional = TieBreakDefault
+)
+
+// SetWidthTieBreak sets which columns get the spare cells when the free space
+// can't be divided exactly between the columns. The default is
+// [TieBreakDefault].
+func (w *Writer) SetWidthTieBreak(tieBreak WidthTieBreak) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tieBreak = tieBreak
+}
+
+// flexItems returns the flex items of the columns, sized to their content.
+func (w *Writer) flexItems() []flex.Item {
+	rowColLengths := transform(w.rows, func(r row) []int {
+		lengths := transform(r.cells, textutil.Width)
+		for i := range lengths {
+			if r.span(i) != 1 {
+				// the spanning cells fit in the columns they span
+				lengths[i] = 0
+				continue
+			}
+			if content := r.cellOpts(i).content; content != nil {
+				lengths[i] = content.PreferredWidth()
+			}
+			first, _ := w.cellPrefixes(r, i)
+			lengths[i] += textutil.Width(first)
+		}
+		return lengths
+	})
+	colRowLengths := transpose(rowColLengths)
+	colLengths := transform(colRowLengths, max)
+
+	nColumns := len(colLengths)
+
+	flexItems := make([]flex.Item, nColumns)
+	for i := 0; i < nColumns; i++ {
+		col := w.getColumnDef(i)
+
+		var minSize int
+		if col.Min > 0 {
+			minSize = col.Min
+		} else if col.wrap == WrapChar {
+			minSize = 1
+		} else if col.wrap == WrapNone {
+			minSize = colLengths[i]
+		} else {
+			minSize = w.colMinContent(i)
+		}
+		if col.Max > 0 && minSize > col.Max {
+			minSize = col.Max
+		}
+		it := col.Item
+		it.Min = minSize
+		it.Size = colLengths[i]
+		if fixed := w.fixedWidth(i); fixed > 0 {
+			it = flex.Item{Basis: Auto, Size: fixed, Min: fixed, Max: fixed}
+		}
+
+		flexItems[i] = it
+	}
+	return flexItems
+}
+
+// WidthsSnapshot returns the widths of the columns at the last flush, not
+// including the decorator; they can be saved and passed to
+// [Writer.SetFixedWidths], e.g. in a later run of the program, so that the
+// output keeps the same layout.
+func (w *Writer) WidthsSnapshot() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]int(nil), w.lastWidths...)
+}
+
+// SetFixedWidths fixes the widths of the first len(widths) visible columns,
+// not including the decorator, regardless of their content and of their
+// configuration; a width of 0 or less leaves the column flexible. The
+// content of the fixed columns is wrapped as needed. Calling SetFixedWidths
+// with nil makes all the columns flexible again.
+func (w *Writer) SetFixedWidths(widths []int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.fixedWidths = append([]int(nil), widths...)
+}
+
+// fixedWidth returns the fixed width of the visible column of the given index,
+// or 0 if it is flexible.
+func (w *Writer) fixedWidth(colIdx int) int {
+	if colIdx < len(w.fixedWidths) && w.fixedWidths[colIdx] > 0 {
+		return w.fixedWidths[colIdx]
+	}
+	return 0
+}
+
+// Layout returns the widths of the columns, not including the decorator, that
+// the buffered rows would have if they were flushed now, without flushing
+// them, e.g. to draw other elements aligned with the columns, like progress
+// bars. Like [Writer.WidthsSnapshot], the widths are in the order of the
+// configuration of the columns. An error is returned if the rows cannot be
+// laid out, as [Writer.Flush] would; there are no widths in plain mode.
+func (w *Writer) Layout() ([]int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.refreshWidth()
+	// the buffered rows are left as they are: they are copied as preparing
+	// and laying them out modifies them
+	rows, buffer := w.rows, w.buffer
+	w.rows = append([]row(nil), rows...)
+	defer func() { w.rows, w.buffer = rows, buffer }()
+	w.prepareRows()
+	if w.plainDelim != "" {
+		return nil, nil
+	}
+
+	l, restore, err := w.resolveLayout()
+	defer restore()
+	if err != nil {
+		return nil, err
+	}
+	return w.configWidths(l.widths), nil
+}
+
+// resolveLayout computes the layout of the buffered rows for a flush. The
+// configuration may be modified for the flush, until restore is called.
+func (w *Writer) resolveLayout() (l layout, restore func(), err error) {
+	var restores []func()
+	restore = func() {
+		for i := len(restores) - 1; i >= 0; i-- {
+			restores[i]()
+		}
+	}
+	if w.streamContinues() {
+		fixedWidths := w.fixedWidths
+		w.fixedWidths = w.stream.widths
+		restores = append(restores, func() { w.fixedWidths = fixedWidths })
+	}
+	w.expandSections()
+	// the columns of a band are already marked, see selectColumns
+	if !w.inBand {
+		restores = append(restores, w.markWritten())
+	}
+	restores = append(restores, w.reorder())
+	if w.mirrored {
+		restores = append(restores, w.mirror())
+	}
+	if w.hasTree() {
+		w.computeTreePrefixes()
+	}
+	l, err = w.computeLayout()
+	if err != nil {
+		return layout{}, restore, err
+	}
+	// the columns are only frozen and dropped on overflow, so that the tables
+	// that fit are laid out as usual
+	freeze := w.overflow == OverflowClip && len(w.frozen) > 0 || w.hasPriorities()
+	if freeze && totalWidth(l.deco, l.outerWidths()) > w.targetWidth() {
+		restores = append(restores, w.markFrozen())
+		l, err = w.dropColumns(l)
+		if err != nil {
+			return layout{}, restore, err
+		}
+	}
+	return l, restore, nil
+}
+
+// markWritten marks the visible columns with their index as written, before
+// they are reordered, mirrored or dropped; it returns a function that restores
+// the configuration, to be called once the rows are flushed.
+func (w *Writer) markWritten() (restore func()) {
+	var n int
+	for _, r := range w.rows {
+		if len(r.cells) > n {
+			n = len(r.cells)
+		}
+	}
+
+	columns := w.columns
+	w.columns = make([]flexItem, n)
+	for ci := range w.columns {
+		w.columns[ci] = w.getColumnDefAt(columns, ci)
+		w.columns[ci].written = w.writtenIndex(ci)
+	}
+
+	return func() {
+		w.columns = columns
+	}
+}
+
+// layout holds the sizing of the columns, as computed when flushing.
+type layout struct {
+	deco    Decorator // the decorator, possibly with collapsed gaps
+	widths  []int     // widths of the content of the columns
+	pads    []int     // extra padding after each column
+	indent  int       // extra padding before the first column
+	dropped int       // number of columns dropped, see SetFrozenColumns
+}
+
+// outerWidths returns the widths of the columns including their padding.
+func (l layout) outerWidths() []int {
+	widths := make([]int, len(l.widths))
+	for i := range l.widths {
+		widths[i] = l.widths[i] + l.pads[i]
+	}
+	if len(widths) > 0 {
+		widths[0] += l.indent
+	}
+	return widths
+}
+
+// computeWidths resolves the widths of the columns so that, along with the
+// decorator, they fit in the target width.
+func (w *Writer) computeWidths(items []flex.Item, deco Decorator) (layout, error) {
+	n := len(items)
+	l := layout{deco: deco, pads: make([]int, n)}
+	container := decoratorContainer(deco, n, w.targetWidth())
+	freeSpace := container.ItemsSize(n)
+
+	// the items are copied as they are modified by the resolution
+	items = append([]flex.Item(nil), items...)
+	for i := range items {
+		if pct := w.getColumnDef(i).percent; pct > 0 && freeSpace > 0 && w.fixedWidth(i) == 0 {
+			items[i].Basis = freeSpace * pct / 100
+		}
+	}
+
+	if w.gap == (Gap{}) || n == 0 {
+		widths, err := flex.ResolveContainer(items, container, flex.TieBreak(w.tieBreak))
+		if err != nil {
+			return l, fmt.Errorf("flexwriter: cannot resolve the widths of %d columns: %w", n, err)
+		}
+		l.widths = widths
+	} else {
+		// the gaps are interleaved with the columns; as flex items can't be
+		// empty, each gap has a phantom cell that is added to the free space
+		// and removed from the result
+		gapItem := func(grow int) flex.Item {
+			it := flex.Item{Basis: Auto, Grow: grow * weightScale, Min: w.gap.Min + 1, Size: w.gap.Min + 1}
+			if w.gap.Max > 0 {
+				it.Max = w.gap.Max + 1
+			}
+			return it
+		}
+		all := []flex.Item{gapItem(w.gap.OuterGrow)}
+		for i, it := range items {
+			if i != 0 {
+				all = append(all, gapItem(w.gap.Grow))
+			}
+			all = append(all, it)
+		}
+		all = append(all, gapItem(w.gap.OuterGrow))
+
+		sizes, err := flex.ResolveTieBreak(all, freeSpace+n+1, flex.TieBreak(w.tieBreak))
+		if err != nil {
+			return l, fmt.Errorf("flexwriter: cannot resolve the widths of %d columns and their gaps: %w", n, err)
+		}
+		l.indent = sizes[0] - 1
+		l.widths = make([]int, n)
+		for i := range items {
+			l.widths[i] = sizes[2*i+1]
+			l.pads[i] = sizes[2*i+2] - 1
+		}
+	}
+
+	// equal columns may differ by one because of the remainder of the division
+	// of the free space, shrink them to the smallest one
+	equalWidth := -1
+	isEqual := func(i int) bool {
+		return w.getColumnDef(i).equal && w.fixedWidth(i) == 0
+	}
+	for i, width := range l.widths {
+		if isEqual(i) && (equalWidth == -1 || width < equalWidth) {
+			equalWidth = width
+		}
+	}
+	for i := range l.widths {
+		if isEqual(i) {
+			l.widths[i] = equalWidth
+		}
+	}
+	return l, nil
+}
+
+// collapse collapses the gaps between the columns, as evenly as possible and
+// only as much as needed for the content to not be wrapped nor overflow the
+// target width.
+func (w *Writer) collapse(items []flex.Item, l layout) (layout, error) {
+	deco := collapsedDecorator{parent: w.deco, collapse: make([]int, len(items))}
+	available := make([]int, len(items))
+	for i := 1; i < len(items); i++ {
+		available[i] = collapsible(w.deco.ColumnSeparator(0, i))
+	}
+
+	for totalWidth(l.deco, l.outerWidths()) > w.targetWidth() || wrapsContent(items, l.widths) {
+		// remove a cell from the least collapsed gap that can still collapse
+		gap := -1
+		for i := 1; i < len(items); i++ {
+			if deco.collapse[i] < available[i] && (gap == -1 || deco.collapse[i] < deco.collapse[gap]) {
+				gap = i
+			}
+		}
+		if gap == -1 {
+			break
+		}
+		deco.collapse[gap]++
+		var err error
+		l, err = w.computeWidths(items, deco)
+		if err != nil {
+			return l, err
+		}
+	}
+	return l, nil
+}
+
+// totalWidth returns the width of the output for columns of the given widths,
+// decorated with the given decorator.
+func totalWidth(deco Decorator, widths []int) int {
+	total := decoratorWidth(deco, len(widths))
+	for _, width := range widths {
+		total += width
+	}
+	return total
+}
+
+// wrapsContent returns whether any of the columns, with the given widths, is
+// too narrow for its content.
+func wrapsContent(items []flex.Item, widths []int) bool {
+	for i, it := range items {
+		size := it.Size
+		if it.Max > 0 && size > it.Max {
+			size = it.Max
+		}
+		if widths[i] < size {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Writer) computeLayout() (layout, error) {
+	items := w.flexItems()
+	l, err := w.computeWidths(items, w.deco)
+	if err != nil {
+		return l, err
+	}
+	if w.collapseGaps {
+		l, err = w.collapse(items, l)
+		if err != nil {
+			return l, err
+		}
+	}
+	w.stretch(&l)
+	return l, nil
+}
@@ -0,0 +1,31 @@
+package flexwriter
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// cellURL returns the URL of a cell, from the link template of its column; it
+// returns the empty string for an empty cell.
+func cellURL(template, cell string) string {
+	value := strings.TrimSpace(textutil.StripEscapes(cell))
+	if value == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, "{cell}", url.PathEscape(value))
+}
+
+// linkCell makes an OSC 8 hyperlink of a line of a cell; the spaces around the
+// content, i.e. its padding, are left out of the link.
+func linkCell(line, url string) string {
+	content := strings.Trim(line, " ")
+	if url == "" || content == "" {
+		return line
+	}
+	start := strings.Index(line, content)
+	return line[:start] +
+		"\x1b]8;;" + url + "\x1b\\" + content + "\x1b]8;;\x1b\\" +
+		line[start+len(content):]
+}
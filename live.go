@@ -0,0 +1,79 @@
+package flexwriter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// SetLive enables or disables the live mode. In this mode, each flush redraws
+// the output in place: the lines written by the previous flush are erased
+// first, with ANSI escape sequences that move the cursor up and clear the
+// screen below it, so that a table updated periodically, e.g. a status table
+// like the one of "docker stats", is rewritten over itself. The rows must
+// then be written again before each flush. Nothing else must be written to
+// the output between the flushes, otherwise the wrong lines are erased. If the
+// terminal is resized in between (see [Writer.SetAutoWidth]), the lines that
+// were wrapped by the terminal are taken into account. Disabling the live mode
+// leaves the last output as it is. By default, the live mode is disabled.
+func (w *Writer) SetLive(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.live = enabled
+	w.liveWidths = nil
+}
+
+// clearLive erases the lines written by the previous flush in live mode.
+func (w *Writer) clearLive() error {
+	var rows int
+	for _, width := range w.liveWidths {
+		// the lines wider than the terminal, e.g. after it was narrowed, span
+		// several rows
+		if w.width > 0 && width > w.width {
+			rows += (width + w.width - 1) / w.width
+		} else {
+			rows++
+		}
+	}
+	w.liveWidths = nil
+	if rows == 0 || w.testing {
+		return nil
+	}
+	_, err := w.writeOutput([]byte(fmt.Sprintf("\r\x1b[%dA\x1b[J", rows)))
+	return err
+}
+
+// trackLive records the widths of the lines written to the output until the
+// returned function is called, so that they can be erased by the next flush.
+func (w *Writer) trackLive() (stop func()) {
+	out := &liveOutput{out: w.output}
+	w.output = out
+	return func() {
+		w.output = out.out
+		w.liveWidths = out.widths
+	}
+}
+
+// liveOutput is an output that records the widths of the lines written to it.
+type liveOutput struct {
+	out    io.Writer
+	widths []int  // widths of the complete lines written
+	line   []byte // incomplete last line
+}
+
+func (o *liveOutput) Write(b []byte) (int, error) {
+	n, err := o.out.Write(b)
+	o.line = append(o.line, b[:n]...)
+	for {
+		i := bytes.IndexByte(o.line, '\n')
+		if i < 0 {
+			break
+		}
+		o.widths = append(o.widths, textutil.Width(string(o.line[:i])))
+		o.line = o.line[i+1:]
+	}
+	return n, err
+}
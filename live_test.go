@@ -0,0 +1,35 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLive(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(10)
+	writer.SetLive(true)
+
+	writer.WriteRow("cpu", "3%")
+	writer.WriteRow("mem", "10%")
+	writer.Flush()
+	assert.Equal(t, "cpu  3%\nmem  10%\n", buf.String())
+
+	buf.Reset()
+	writer.WriteRow("cpu", "5%")
+	writer.Flush()
+	assert.Equal(t, "\r\x1b[2A\x1b[Jcpu  5%\n", buf.String())
+
+	// the lines wider than the terminal span several rows
+	buf.Reset()
+	writer.WriteRow("a very long line that is wrapped by the terminal")
+	writer.SetWidth(100)
+	writer.Flush()
+	writer.SetWidth(10)
+	writer.Flush()
+	assert.Equal(t, "\r\x1b[1A\x1b[Ja very long line that is wrapped by the terminal\n\r\x1b[5A\x1b[J", buf.String())
+}
@@ -0,0 +1,95 @@
+package flexwriter
+
+// SetMirror sets whether the columns are mirrored, for right-to-left
+// languages: the first column is the right-most one, and so on, and the Left
+// and Right alignments are swapped, including those of the cells. The order of
+// the cells given to the writer, and the indexes of the columns in its
+// configuration, are left unchanged. By default, the columns are not mirrored.
+//
+// As the mirroring only applies when flushing, it can be switched at any time.
+func (w *Writer) SetMirror(mirror bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.mirrored = mirror
+}
+
+// mirrorAlignment swaps the Left and Right alignments.
+func mirrorAlignment(align Alignment) Alignment {
+	switch align {
+	case Left:
+		return Right
+	case Right:
+		return Left
+	}
+	return align
+}
+
+// mirror reverses the buffered rows and the configuration of the visible
+// columns; it returns a function that restores the configuration, to be called
+// once the rows are flushed.
+func (w *Writer) mirror() (restore func()) {
+	var n int
+	for _, r := range w.rows {
+		if len(r.cells) > n {
+			n = len(r.cells)
+		}
+	}
+
+	// the rows are copied so that they are kept as written if the output
+	// fails, see WriteError
+	w.rows = append([]row(nil), w.rows...)
+	for i, r := range w.rows {
+		if r.rule || len(r.cells) == 0 {
+			continue
+		}
+		cells := make([]string, n)
+		opts := make([]cellOpts, n)
+		for ci := 0; ci < n; ci++ {
+			if ci < len(r.cells) {
+				cells[n-1-ci] = r.cells[ci]
+			}
+			opt := r.cellOpts(ci)
+			if opt.align != nil {
+				align := mirrorAlignment(*opt.align)
+				opt.align = &align
+			}
+			opts[n-1-ci] = opt
+		}
+		if r.spans != nil {
+			// a spanning cell moves to the other end of the columns it spans
+			spans := make([]int, n)
+			for ci, span := range r.spans {
+				if span < 2 || ci >= n {
+					continue
+				}
+				end := ci + span - 1
+				if end >= n {
+					end = n - 1
+				}
+				first := n - 1 - end
+				spans[first] = end - ci + 1
+				cells[first], cells[n-1-ci] = cells[n-1-ci], cells[first]
+				opts[first], opts[n-1-ci] = opts[n-1-ci], opts[first]
+			}
+			w.rows[i].spans = spans
+		}
+		w.rows[i].cells, w.rows[i].opts = cells, opts
+	}
+
+	columns, fixedWidths := w.columns, w.fixedWidths
+	w.columns = make([]flexItem, n)
+	w.fixedWidths = make([]int, n)
+	for ci := 0; ci < n; ci++ {
+		col := w.getColumnDefAt(columns, ci)
+		col.Alignment = mirrorAlignment(col.Alignment)
+		w.columns[n-1-ci] = col
+		if ci < len(fixedWidths) {
+			w.fixedWidths[n-1-ci] = fixedWidths[ci]
+		}
+	}
+
+	return func() {
+		w.columns, w.fixedWidths = columns, fixedWidths
+	}
+}
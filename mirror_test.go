@@ -0,0 +1,29 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirror(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{Align: Right}, Rigid{Min: 4})
+	writer.SetMirror(true)
+
+	writer.WriteRow("name", 1, "x")
+	writer.WriteRow("longer name", 1234)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+------+------+-------------+\n"+
+		"|    x | 1    |        name |\n"+
+		"+------+------+-------------+\n"+
+		"|      | 1234 | longer name |\n"+
+		"+------+------+-------------+\n", buf.String())
+	assert.Equal(t, []int{11, 4, 4}, writer.WidthsSnapshot())
+}
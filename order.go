@@ -0,0 +1,84 @@
+package flexwriter
+
+import "github.com/hchargois/flexwriter/flex"
+
+// reorder lays out the buffered rows and the configuration of the visible
+// columns in the order set by the Order of the columns, if any column has a
+// non-zero Order; it returns a function that restores the configuration, to
+// be called once the rows are flushed.
+func (w *Writer) reorder() (restore func()) {
+	var n int
+	for _, r := range w.rows {
+		if len(r.cells) > n {
+			n = len(r.cells)
+		}
+	}
+
+	columns, fixedWidths := w.columns, w.fixedWidths
+	items := make([]flex.Item, n)
+	var ordered bool
+	for ci := range items {
+		items[ci] = w.getColumnDefAt(columns, ci).Item
+		if items[ci].Order != 0 {
+			ordered = true
+		}
+	}
+	if !ordered {
+		return func() {}
+	}
+	order := flex.Ordered(items)
+
+	// the rows are copied so that they are kept as written if the output
+	// fails, see WriteError
+	w.rows = append([]row(nil), w.rows...)
+	for i, r := range w.rows {
+		if r.rule || len(r.cells) == 0 || r.kind == SectionRow {
+			// a section spans all the columns, whatever their order
+			continue
+		}
+		cells := make([]string, n)
+		opts := make([]cellOpts, n)
+		for pos, ci := range order {
+			if ci < len(r.cells) {
+				cells[pos] = r.cells[ci]
+			}
+			opts[pos] = r.cellOpts(ci)
+		}
+		w.rows[i].cells, w.rows[i].opts = cells, opts
+		w.rows[i].spans = selectSpans(r.spans, order)
+	}
+
+	w.columns = make([]flexItem, n)
+	w.fixedWidths = make([]int, n)
+	for pos, ci := range order {
+		w.columns[pos] = w.getColumnDefAt(columns, ci)
+		if ci < len(fixedWidths) {
+			w.fixedWidths[pos] = fixedWidths[ci]
+		}
+	}
+	w.colOrder = order
+
+	return func() {
+		w.columns, w.fixedWidths, w.colOrder = columns, fixedWidths, nil
+	}
+}
+
+// configWidths returns the widths of the columns as laid out, in the order of
+// the configuration of the columns, so that they can be given back to
+// [Writer.SetFixedWidths].
+func (w *Writer) configWidths(widths []int) []int {
+	widths = append([]int(nil), widths...)
+	if w.mirrored {
+		reverse(widths)
+	}
+	if w.colOrder == nil {
+		return widths
+	}
+	config := make([]int, len(widths))
+	for pos, ci := range w.colOrder {
+		if pos < len(widths) && ci < len(config) {
+			config[ci] = widths[pos]
+		}
+	}
+	return config
+}
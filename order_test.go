@@ -0,0 +1,55 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnOrder(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{Align: Right, Order: -1}, Rigid{Min: 4})
+	writer.SetHeaders("name", "id", "tag")
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteRow("name", 1, "x")
+	writer.WriteRow("longer name", 1234)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"+------+-------------+------+\n"+
+		"|   id | name        | tag  |\n"+
+		"+======+=============+======+\n"+
+		"|    1 | name        | x    |\n"+
+		"+------+-------------+------+\n"+
+		"| 1234 | longer name |      |\n"+
+		"+------+-------------+------+\n", buf.String())
+	assert.Equal(t, []int{11, 4, 4}, writer.WidthsSnapshot())
+}
+
+func TestColumnOrderFill(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(20)
+	writer.SetColumns(Rigid{Order: 1}, Rigid{}, Rigid{})
+	writer.SetPadTrailing(true)
+	writer.SetFill(FillColumn{Index: 0})
+
+	// the index of the column is the written one, not its position
+	writer.WriteRow("A", "B", "C")
+	writer.Flush()
+
+	assert.Equal(t, "B  C  A             \n", buf.String())
+
+	buf.Reset()
+	writer.SetFill(FillColumn{Index: 1})
+	writer.WriteRow("A", "B", "C")
+	writer.Flush()
+
+	assert.Equal(t, "B               C  A\n", buf.String())
+}
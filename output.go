@@ -0,0 +1,91 @@
+package flexwriter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// WriteError is returned when flushing if the output fails. Nothing is written
+// again by the writer: if no byte reached the output, the rows are kept in the
+// buffer so that the flush can be retried, e.g. once the output is available
+// again; otherwise, all the rows are discarded, as writing them again would
+// repeat the rows that were already written.
+type WriteError struct {
+	// Rows is the number of rows completely written before the failure, not
+	// including the separators.
+	Rows int
+	// Lines is the number of lines completely written before the failure,
+	// including the separators and the caption.
+	Lines int
+	// Kept is whether the rows are still in the buffer.
+	Kept bool
+	// Err is the error returned by the output.
+	Err error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("flexwriter: cannot write the output after %d rows (%d lines): %v", e.Rows, e.Lines, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// writeOutput writes b to the output; a short write without an error is
+// retried with the remaining bytes. It returns the number of bytes written.
+func (w *Writer) writeOutput(b []byte) (int, error) {
+	var written int
+	for written < len(b) {
+		n, err := w.output.Write(b[written:])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if n == 0 {
+			return written, io.ErrShortWrite
+		}
+	}
+	return written, nil
+}
+
+// outputTracker keeps track of how much of the output of a flush is written,
+// so that a failure can be reported precisely.
+type outputTracker struct {
+	rows      []row // the rows being flushed, kept if nothing is written
+	written   int   // number of bytes written
+	lines     int   // number of lines written
+	rowStarts []int // offsets of the starts of the rows in the output
+}
+
+// startRow records that a row starts at the given offset of the output,
+// counted from the start of the flush; it must also be called once after the
+// last row, for its end.
+func (t *outputTracker) startRow(offset int) {
+	t.rowStarts = append(t.rowStarts, offset)
+}
+
+// write writes b, the part of the output following what is already written.
+// On failure, it returns a *WriteError, and keeps the rows in the buffer if
+// nothing at all was written, or discards them otherwise.
+func (t *outputTracker) write(w *Writer, b []byte) error {
+	n, err := w.writeOutput(b)
+	t.written += n
+	t.lines += bytes.Count(b[:n], []byte{'\n'})
+	if err == nil {
+		return nil
+	}
+
+	werr := &WriteError{Lines: t.lines, Kept: t.written == 0, Err: err}
+	// a row is written once the next one starts within the written bytes
+	for i := 1; i < len(t.rowStarts); i++ {
+		if t.rowStarts[i] <= t.written {
+			werr.Rows++
+		}
+	}
+	w.rows = nil
+	if werr.Kept {
+		w.rows = t.rows
+	}
+	return werr
+}
@@ -0,0 +1,73 @@
+package flexwriter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// shortWriter writes at most size bytes at a time, and fails once it has
+// written limit bytes.
+type shortWriter struct {
+	bytes.Buffer
+	size  int
+	limit int
+}
+
+func (w *shortWriter) Write(b []byte) (int, error) {
+	if w.Len() >= w.limit {
+		return 0, io.ErrClosedPipe
+	}
+	if len(b) > w.size {
+		b = b[:w.size]
+	}
+	return w.Buffer.Write(b)
+}
+
+func TestShortWrites(t *testing.T) {
+	out := &shortWriter{size: 3, limit: 100}
+	writer := New()
+	writer.SetOutput(out)
+
+	writer.WriteRow("aaa", "bbb")
+	writer.WriteRow("ccc", "ddd")
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "aaa  bbb\nccc  ddd\n", out.String())
+}
+
+func TestWriteError(t *testing.T) {
+	out := &shortWriter{size: 3, limit: 10}
+	writer := New()
+	writer.SetOutput(out)
+
+	writer.WriteRow("aaa", "bbb")
+	writer.WriteRow("ccc", "ddd")
+	err := writer.Flush()
+	var werr *WriteError
+	assert.True(t, errors.As(err, &werr))
+	assert.Equal(t, &WriteError{Rows: 1, Lines: 1, Err: io.ErrClosedPipe}, werr)
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+	assert.Equal(t, "flexwriter: cannot write the output after 1 rows (1 lines): io: read/write on closed pipe", err.Error())
+	assert.Equal(t, "aaa  bbb\nccc", out.String())
+	assert.Equal(t, 0, writer.Stats().BufferedRows)
+}
+
+func TestWriteErrorKeepsRows(t *testing.T) {
+	out := &shortWriter{size: 3, limit: 0}
+	writer := New()
+	writer.SetOutput(out)
+	writer.SetMirror(true)
+
+	writer.WriteRow("a", "bbb")
+	err := writer.Flush()
+	assert.Equal(t, &WriteError{Kept: true, Err: io.ErrClosedPipe}, err)
+	assert.Equal(t, 1, writer.Stats().BufferedRows)
+
+	// the flush can be retried, the rows are not mirrored twice
+	out.limit = 100
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "bbb  a\n", out.String())
+}
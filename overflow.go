@@ -0,0 +1,121 @@
+package flexwriter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// OverflowPolicy defines what happens when the columns can't shrink enough to
+// fit in the target width, e.g. because of their min widths.
+type OverflowPolicy int
+
+const (
+	// OverflowWrap lets the lines be longer than the target width; if the
+	// output is a terminal, they will be wrapped by the terminal itself.
+	// This is the default.
+	OverflowWrap OverflowPolicy = iota
+	// OverflowClip clips the lines to the target width.
+	// See also [Writer.SetClipMarker] and [Writer.SetOverflowIndicator].
+	OverflowClip
+	// OverflowError makes [Writer.Flush] return an error wrapping
+	// [ErrOverflow], without writing anything.
+	OverflowError
+)
+
+// ErrOverflow is returned by [Writer.Flush] when the policy is OverflowError
+// and the content doesn't fit in the target width.
+var ErrOverflow = errors.New("flexwriter: content does not fit in the target width")
+
+// SetOverflowPolicy sets what happens when the columns can't shrink enough to
+// fit in the target width. The default is [OverflowWrap].
+func (w *Writer) SetOverflowPolicy(policy OverflowPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.overflow = policy
+}
+
+// SetEnforceWidth sets whether the output is strictly limited to the target
+// width. When the columns can't shrink enough to fit in the target width
+// (e.g. because of their min widths), the lines are normally longer than the
+// target width; if enforce is true they are instead clipped to the target width.
+//
+// This is a shortcut for setting the overflow policy to [OverflowClip] if
+// enforce is true, or back to [OverflowWrap] otherwise if it was OverflowClip;
+// an [OverflowError] policy is left as it is.
+func (w *Writer) SetEnforceWidth(enforce bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if enforce {
+		w.overflow = OverflowClip
+	} else if w.overflow == OverflowClip {
+		w.overflow = OverflowWrap
+	}
+}
+
+// SetClipMarker sets a marker, e.g. "…", that replaces the end of the lines
+// clipped because of the [OverflowClip] policy. By default, there is no marker.
+func (w *Writer) SetClipMarker(marker string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.clipMarker = marker
+}
+
+// SetOverflowIndicator sets an indicator appended at the right edge of each
+// row when columns are clipped because of the [OverflowClip] policy, or are
+// dropped whatever the policy (see [Writer.SetFrozenColumns] and the Priority
+// of [Column]). The format is passed to [fmt.Sprintf] with the number of
+// hidden columns, e.g. " ▶ +%d cols". Columns that are only partially visible
+// are counted as hidden. The indicator takes precedence over the clip marker for rows, but not for
+// row separators. By default, there is no indicator.
+func (w *Writer) SetOverflowIndicator(format string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.indicator = format
+}
+
+// checkOverflow returns an error if the columns of the layout don't fit in the
+// target width.
+func (w *Writer) checkOverflow(l layout) error {
+	if total := totalWidth(l.deco, l.outerWidths()); total > w.targetWidth() {
+		return fmt.Errorf("%w: %d columns need a width of %d, but the target width is %d",
+			ErrOverflow, len(l.widths), total, w.targetWidth())
+	}
+	return nil
+}
+
+// hiddenColumns returns the number of columns of the layout that don't
+// entirely fit within the first limit cells of the output.
+func (w *Writer) hiddenColumns(l layout, limit int) int {
+	widths := l.outerWidths()
+	pos := textutil.Width(l.deco.ColumnSeparator(0, 0))
+	for ci, width := range widths {
+		pos += width
+		if pos > limit {
+			return len(widths) - ci
+		}
+		if ci != len(widths)-1 {
+			pos += textutil.Width(l.deco.ColumnSeparator(0, ci+1))
+		}
+	}
+	return 0
+}
+
+// overflowIndicator returns the hidden columns indicator, or the empty string
+// if all columns of the layout are visible.
+func (w *Writer) overflowIndicator(l layout) string {
+	width := w.targetWidth()
+	hidden := w.hiddenColumns(l, width) + l.dropped
+	if hidden == 0 {
+		return ""
+	}
+	// the indicator itself takes some space and may hide more columns
+	indicator := fmt.Sprintf(w.indicator, hidden)
+	hidden = w.hiddenColumns(l, width-textutil.Width(indicator)) + l.dropped
+	return fmt.Sprintf(w.indicator, hidden)
+}
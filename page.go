@@ -0,0 +1,164 @@
+package flexwriter
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetPageSize sets the number of rows of the pages written by
+// [Writer.RenderPage], not counting the header rows that are repeated at the
+// top of each page. If n is 0, which is the default, there is a single page
+// with all the rows. The page size doesn't change what [Writer.Flush] writes.
+func (w *Writer) SetPageSize(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pageSize = n
+}
+
+// Pages returns the number of pages of the buffered rows, see
+// [Writer.RenderPage]; there is always at least one page, which may be empty.
+func (w *Writer) Pages() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.prepareRows()
+	_, pages := w.pages()
+	return len(pages)
+}
+
+// SetPageFooter sets the format of the footer written below each page by
+// [Writer.RenderPage]; it is passed to [fmt.Sprintf] with the index of the
+// page (starting at 1) and the number of pages. The default is "page %d/%d";
+// an empty format writes no footer.
+func (w *Writer) SetPageFooter(format string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pageFooter = format
+}
+
+// RenderPage writes the page of index k (starting at 1) of the buffered rows
+// to the output, followed by a footer, by default "page k/N" (see
+// [Writer.SetPageFooter]); the header rows at the top
+// of the buffer are repeated at the top of each page. See also
+// [Writer.SetSplitMarkers]. The columns have the
+// same widths on all the pages, as if all the rows were flushed at once.
+//
+// Unlike [Writer.Flush], RenderPage keeps the rows in the buffer, so that the
+// pages can be written in any order, e.g. by an interactive pager; use
+// [Writer.Reset] to discard them.
+func (w *Writer) RenderPage(k int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.prepareRows()
+	headers, pages := w.pages()
+	if k < 1 || k > len(pages) {
+		return fmt.Errorf("flexwriter: page %d is out of range, there are %d pages", k, len(pages))
+	}
+
+	rows, output, fixedWidths := w.rows, w.output, w.fixedWidths
+	defer func() {
+		w.rows, w.output, w.fixedWidths = rows, output, fixedWidths
+	}()
+
+	// all the rows are laid out first, without writing them, to get the
+	// widths of the columns; the rows are copied as flushing modifies them
+	w.rows = append([]row(nil), rows...)
+	w.output = io.Discard
+	if _, err := w.flush(); err != nil {
+		return err
+	}
+
+	w.fixedWidths = w.lastWidths
+	w.rows = append(append([]row(nil), headers...), pages[k-1]...)
+	w.output = output
+	if k > 1 {
+		if _, err := w.writeSplitMarker(w.splitMarkers.Header); err != nil {
+			return err
+		}
+	}
+	if _, err := w.flush(); err != nil {
+		return err
+	}
+	if k < len(pages) {
+		if _, err := w.writeSplitMarker(w.splitMarkers.Footer); err != nil {
+			return err
+		}
+	}
+	if w.pageFooter == "" {
+		return nil
+	}
+	_, err := w.writeOutput([]byte(fmt.Sprintf(w.pageFooter, k, len(pages)) + "\n"))
+	return err
+}
+
+// Reset discards the buffered rows, without writing them.
+func (w *Writer) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buffer = nil
+	w.rows = nil
+}
+
+// pages splits the buffered rows into pages of the page size; the header rows
+// at the top of the buffer are returned apart, as they are repeated on each
+// page.
+func (w *Writer) pages() (headers []row, pages [][]row) {
+	rows := w.rows
+	for len(rows) > 0 && !rows[0].rule && rows[0].kind == HeaderRow {
+		headers = append(headers, rows[0])
+		rows = rows[1:]
+	}
+
+	var page []row
+	var n int
+	for _, r := range rows {
+		if r.rule {
+			// a separator is useless at the top of a page
+			if len(page) > 0 {
+				page = append(page, r)
+			}
+			continue
+		}
+		if w.pageSize > 0 && n == w.pageSize {
+			pages = append(pages, page)
+			page, n = nil, 0
+		}
+		page = append(page, r)
+		n++
+	}
+	return headers, append(pages, page)
+}
+
+// SplitMarkers are the markers written where a table is split, either in
+// pages (see [Writer.RenderPage]) or in bands (see [Writer.SetBands]), so that
+// the readers of e.g. printed output know that the parts go together.
+type SplitMarkers struct {
+	// Header is written above each part but the first, e.g. "(continued)".
+	Header string
+	// Footer is written below each part but the last, e.g. "(continued
+	// below)"; for pages, it is written above the page number.
+	Footer string
+}
+
+// SetSplitMarkers sets the markers written where a table is split. By default,
+// there are no markers.
+func (w *Writer) SetSplitMarkers(markers SplitMarkers) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.splitMarkers = markers
+}
+
+// writeSplitMarker writes the given marker on its own line, unless it is
+// empty; it returns the number of lines written.
+func (w *Writer) writeSplitMarker(marker string) (int, error) {
+	if marker == "" {
+		return 0, nil
+	}
+	_, err := w.writeOutput([]byte(marker + "\n"))
+	return 1, err
+}
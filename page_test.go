@@ -0,0 +1,72 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPage(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetPageSize(2)
+
+	writer.WriteRow("a", "1")
+	writer.WriteRow("b", "2")
+	writer.WriteRow("longer", "3")
+	assert.Equal(t, 2, writer.Pages())
+
+	assert.NoError(t, writer.RenderPage(2))
+	assert.NoError(t, writer.RenderPage(1))
+	assert.Error(t, writer.RenderPage(3))
+
+	// the columns have the same widths on all the pages
+	assert.Equal(t, ""+
+		"+--------+---+\n"+
+		"| longer | 3 |\n"+
+		"+--------+---+\n"+
+		"page 2/2\n"+
+		"+--------+---+\n"+
+		"| a      | 1 |\n"+
+		"+--------+---+\n"+
+		"| b      | 2 |\n"+
+		"+--------+---+\n"+
+		"page 1/2\n", buf.String())
+
+	buf.Reset()
+	writer.SetSplitMarkers(SplitMarkers{Header: "(continued)", Footer: "(continued on next page)"})
+	assert.NoError(t, writer.RenderPage(1))
+	assert.NoError(t, writer.RenderPage(2))
+	assert.Equal(t, ""+
+		"+--------+---+\n"+
+		"| a      | 1 |\n"+
+		"+--------+---+\n"+
+		"| b      | 2 |\n"+
+		"+--------+---+\n"+
+		"(continued on next page)\n"+
+		"page 1/2\n"+
+		"(continued)\n"+
+		"+--------+---+\n"+
+		"| longer | 3 |\n"+
+		"+--------+---+\n"+
+		"page 2/2\n", buf.String())
+
+	buf.Reset()
+	writer.SetPageFooter("-- %d of %d --")
+	assert.NoError(t, writer.RenderPage(2))
+	assert.Equal(t, ""+
+		"(continued)\n"+
+		"+--------+---+\n"+
+		"| longer | 3 |\n"+
+		"+--------+---+\n"+
+		"-- 2 of 2 --\n", buf.String())
+
+	// the rows are still buffered
+	assert.Equal(t, 3, writer.Stats().BufferedRows)
+	writer.Reset()
+	assert.Equal(t, 0, writer.Stats().BufferedRows)
+	assert.Equal(t, 1, writer.Pages())
+}
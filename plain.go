@@ -0,0 +1,74 @@
+package flexwriter
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/hchargois/flexwriter/textutil"
+)
+
+// SetPlainMode switches the writer to a plain mode, friendly to screen readers
+// and dumb terminals, or back to the normal mode if delimiter is empty. In
+// plain mode, the columns are not aligned: each row is written on a single
+// line, with its cells separated by the delimiter, e.g. " | ". Nothing is
+// wrapped nor padded, the decorator is not used, and escape sequences such as
+// colors are removed. The caption, if any, is written on its own line.
+//
+// As the mode only applies when flushing, it can be switched at any time.
+func (w *Writer) SetPlainMode(delimiter string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.plainDelim = delimiter
+}
+
+// flushPlain writes the rows in plain mode.
+func (w *Writer) flushPlain() (FlushStats, error) {
+	var stats FlushStats
+	var out bytes.Buffer
+	track := outputTracker{rows: w.rows}
+	writeLine := func(line string) {
+		line = textutil.StripEscapes(line)
+		if w.trimTrailing {
+			line = strings.TrimRightFunc(line, unicode.IsSpace)
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+		stats.Lines++
+	}
+
+	var caption string
+	if w.caption.Text != "" && len(w.rows) > 0 {
+		caption = strings.Join(strings.Fields(w.caption.Text), " ")
+	}
+	if caption != "" && !w.caption.Below {
+		writeLine(caption)
+	}
+	for _, r := range w.rows {
+		if r.rule {
+			continue
+		}
+		track.startRow(out.Len())
+		stats.Rows++
+		cells := make([]string, len(r.cells))
+		for i, cell := range r.cells {
+			if content := r.cellOpts(i).content; content != nil {
+				// there are no columns, the cell can take the whole width
+				cell = strings.Join(content.Render(w.targetWidth()), " ")
+			}
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		writeLine(strings.Join(cells, w.plainDelim))
+	}
+	track.startRow(out.Len())
+	if caption != "" && w.caption.Below {
+		writeLine(caption)
+	}
+
+	if err := track.write(w, out.Bytes()); err != nil {
+		return FlushStats{}, err
+	}
+	w.rows = nil
+	return stats, nil
+}
@@ -0,0 +1,40 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainMode(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(BoxDrawingTableDecorator())
+	writer.SetCaption(Caption{Text: "Fruits  in   stock"})
+	writer.SetPlainMode(" | ")
+
+	writer.WriteRow("\x1b[1mname\x1b[0m", "count")
+	writer.WriteSeparator()
+	writer.WriteRow("apple", "  3")
+	writer.WriteRow("banana split", "12")
+	writer.Flush()
+
+	// back to the normal mode
+	writer.SetPlainMode("")
+	writer.WriteRow("a", "b")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"Fruits in stock\n"+
+		"name | count\n"+
+		"apple | 3\n"+
+		"banana split | 12\n"+
+		"Fruits\n"+
+		"in\n"+
+		"stock\n"+
+		"┌───┬───┐\n"+
+		"│ a │ b │\n"+
+		"└───┴───┘\n", buf.String())
+}
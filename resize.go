@@ -0,0 +1,55 @@
+package flexwriter
+
+import "os"
+
+// SetAutoWidth sets whether the width of the terminal is detected again before
+// each flush, when it may have changed, so that a long-running program that
+// prints tables periodically follows the resizes of the terminal. On Unix
+// systems, the width is only detected again after a SIGWINCH signal, i.e.
+// after the terminal is resized; elsewhere, it is detected at each flush. This
+// has no effect if the output is not a terminal (see [Writer.SetOutput]), and
+// the detected width takes precedence over [Writer.SetWidth]. By default, the
+// width is only detected by SetOutput.
+func (w *Writer) SetAutoWidth(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.setAutoWidth(enabled)
+}
+
+func (w *Writer) setAutoWidth(enabled bool) {
+	if enabled == w.autoWidth {
+		return
+	}
+	w.autoWidth = enabled
+	if !enabled {
+		if w.resized != nil {
+			stopResizeNotify(w.resized)
+			w.resized = nil
+		}
+		return
+	}
+
+	resized := make(chan os.Signal, 1)
+	if notifyResize(resized) {
+		w.resized = resized
+	}
+	// the terminal may have been resized since the output was set
+	w.detectWidth(w.output)
+}
+
+// refreshWidth detects the width of the terminal again if it may have changed,
+// see SetAutoWidth.
+func (w *Writer) refreshWidth() {
+	if !w.autoWidth {
+		return
+	}
+	if w.resized != nil {
+		select {
+		case <-w.resized:
+		default:
+			return
+		}
+	}
+	w.detectWidth(w.output)
+}
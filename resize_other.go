@@ -0,0 +1,14 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package flexwriter
+
+import "os"
+
+// notifyResize relays the resizes of the terminal to c; it returns false if
+// they can't be notified on this system.
+func notifyResize(c chan os.Signal) bool {
+	return false
+}
+
+// stopResizeNotify stops relaying the resizes of the terminal to c.
+func stopResizeNotify(c chan os.Signal) {}
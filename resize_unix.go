@@ -0,0 +1,21 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package flexwriter
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize relays the resizes of the terminal to c; it returns false if
+// they can't be notified on this system.
+func notifyResize(c chan os.Signal) bool {
+	signal.Notify(c, syscall.SIGWINCH)
+	return true
+}
+
+// stopResizeNotify stops relaying the resizes of the terminal to c.
+func stopResizeNotify(c chan os.Signal) {
+	signal.Stop(c)
+}
@@ -0,0 +1,42 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package flexwriter
+
+import (
+	"bytes"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoWidth(t *testing.T) {
+	var buf bytes.Buffer
+	termWidth := 10
+	writer := New()
+	writer.SetTerminalWidthFunc(func(io.Writer) (int, bool) { return termWidth, true })
+	writer.SetOutput(&buf)
+	writer.SetDefaultColumn(Flexed{})
+	writer.SetDecorator(GapDecorator{Gap: "|", Right: "|"})
+	writer.SetAutoWidth(true)
+	defer writer.SetAutoWidth(false)
+
+	// without a resize, the width is not detected again
+	termWidth = 6
+	writer.WriteRow("a", "b")
+	writer.Flush()
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGWINCH))
+	deadline := time.Now().Add(time.Second)
+	for len(writer.resized) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	writer.WriteRow("a", "b")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"a   |b   |\n"+
+		"a |b |\n", buf.String())
+}
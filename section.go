@@ -0,0 +1,90 @@
+package flexwriter
+
+import (
+	"github.com/fatih/color"
+	"github.com/hchargois/flexwriter/textutil"
+	"golang.org/x/text/unicode/norm"
+)
+
+// WriteSection writes a section row, whose single cell spans all the columns,
+// e.g. to introduce a group of rows with a title. The title is left-aligned
+// and wrapped to the width of the whole table; it doesn't widen the columns.
+// If the value is not a string, it is converted to a string using
+// [fmt.Sprint]. The kind of the row is SectionRow, so that a [KindDecorator]
+// can decorate it differently; to surround it with separators when the
+// decorator doesn't separate all the rows, call [Writer.WriteSeparator]
+// before and after it.
+func (w *Writer) WriteSection(title any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushBuffer()
+	left := Left
+	cell := w.cellString(-1, title)
+	if w.normalize {
+		cell = norm.NFC.String(cell)
+	}
+	if w.stripANSI {
+		cell = textutil.StripEscapes(cell)
+	}
+	w.rows = append(w.rows, row{
+		cells: []string{cell},
+		all:   []string{cell},
+		opts:  []cellOpts{{align: &left}},
+		kind:  SectionRow,
+	})
+	w.streamRows()
+}
+
+// SetSectionStyle sets the color of the titles of the section rows, see
+// [Writer.WriteSection]. A nil color, which is the default, leaves them
+// unstyled.
+func (w *Writer) SetSectionStyle(c *color.Color) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.sectionStyle = newStyle(c)
+}
+
+// expandSections makes the cells of the section rows span all the columns,
+// and styles them, before they are laid out.
+func (w *Writer) expandSections() {
+	var n int
+	var sections bool
+	for _, r := range w.rows {
+		if len(r.cells) > n {
+			n = len(r.cells)
+		}
+		if !r.rule && r.kind == SectionRow {
+			sections = true
+		}
+	}
+	if w.streamContinues() && len(w.stream.widths) > n {
+		n = len(w.stream.widths)
+	}
+	if !sections {
+		return
+	}
+
+	// the rows are copied so that they are kept as written if the output
+	// fails, see WriteError
+	w.rows = append([]row(nil), w.rows...)
+	for i, r := range w.rows {
+		if r.rule || r.kind != SectionRow || len(r.cells) == 0 {
+			continue
+		}
+		opt := r.cellOpts(0)
+		if opt.style.isZero() {
+			opt.style = w.sectionStyle
+		}
+		w.rows[i].opts = []cellOpts{opt}
+		if n < 2 {
+			continue
+		}
+		cells := make([]string, n)
+		cells[0] = r.cells[0]
+		spans := make([]int, n)
+		spans[0] = n
+		w.rows[i].cells, w.rows[i].spans = cells, spans
+	}
+}
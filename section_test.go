@@ -0,0 +1,88 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSection(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(BoxDrawingTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{Align: Right})
+	writer.SetHeaders("name", "qty")
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteSection("fruits")
+	writer.WriteRow("pear", 3)
+	writer.WriteRow("fig", 12)
+	writer.WriteSection("vegetables")
+	writer.WriteRow("leek", 1)
+	writer.Flush()
+
+	expected := "" +
+		"┌──────┬─────┐\n" +
+		"│ name │ qty │\n" +
+		"╞══════╪═════╡\n" +
+		"│ fruits     │\n" +
+		"├──────┬─────┤\n" +
+		"│ pear │   3 │\n" +
+		"├──────┼─────┤\n" +
+		"│ fig  │  12 │\n" +
+		"├──────┴─────┤\n" +
+		"│ vegetables │\n" +
+		"├──────┬─────┤\n" +
+		"│ leek │   1 │\n" +
+		"└──────┴─────┘\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteSectionSeparators(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetColumns(Rigid{}, Rigid{Align: Right})
+	writer.SetMirror(true)
+
+	writer.WriteRow("pear", 3)
+	writer.WriteSeparator()
+	writer.WriteSection("long section title")
+	writer.WriteSeparator()
+	writer.WriteRow("fig", 12)
+	writer.Flush()
+
+	expected := "" +
+		"3   pear\n" +
+		"────────\n" +
+		"    long\n" +
+		" section\n" +
+		"   title\n" +
+		"────────\n" +
+		"12   fig\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestSectionStyle(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetSectionStyle(color.New(color.Bold))
+	writer.SetNormalization(true)
+
+	// the title is styled and normalized even with a single column
+	writer.WriteSection("cafe\u0301s")
+	writer.WriteRow("pear")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"\x1b[1mcaf\u00e9s\x1b[22m\n"+
+		"pear\n", buf.String())
+}
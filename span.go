@@ -0,0 +1,192 @@
+package flexwriter
+
+import "github.com/hchargois/flexwriter/textutil"
+
+// SpanCell is a cell spanning several columns, see [Span].
+type SpanCell struct {
+	// N is the number of columns spanned by the cell.
+	N int
+	// Value is the value of the cell, converted as the value of any cell of
+	// its first column.
+	Value any
+}
+
+// Span returns a cell that spans n columns, starting at its own, e.g. for a
+// section title or a "no results" placeholder in a table; the columns it
+// spans are those of the next n-1 cells, so these cells must not be given.
+// The content of the cell is wrapped to the width of the columns it spans,
+// along with the column separators in between, which are not drawn in that
+// row; it doesn't widen the columns. The cell is in the first of its columns
+// for e.g. the formatters, the styles and the alignment, and its content is
+// not written if that column is omitted.
+func Span(n int, value any) SpanCell {
+	return SpanCell{N: n, Value: value}
+}
+
+// expandSpans replaces the spanning cells of a row by their value followed by
+// empty cells for the other columns they span, and returns the spans of the
+// cells, by written index, or nil if no cell spans more than one column. The
+// options of the cells are moved along.
+func expandSpans(cells []any, opts []cellOpts) ([]any, []cellOpts, []int) {
+	var found bool
+	for _, cell := range cells {
+		if _, ok := cell.(SpanCell); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return cells, opts, nil
+	}
+
+	var expanded []any
+	var expandedOpts []cellOpts
+	var spans []int
+	for i, cell := range cells {
+		n := 1
+		if span, ok := cell.(SpanCell); ok {
+			cell = span.Value
+			if span.N > 1 {
+				n = span.N
+			}
+		}
+		expanded = append(expanded, cell)
+		expandedOpts = append(expandedOpts, cellOpts{})
+		if i < len(opts) {
+			expandedOpts[len(expandedOpts)-1] = opts[i]
+		}
+		spans = append(spans, n)
+		for k := 1; k < n; k++ {
+			expanded = append(expanded, "")
+			expandedOpts = append(expandedOpts, cellOpts{})
+			spans = append(spans, 0)
+		}
+	}
+	return expanded, expandedOpts, spans
+}
+
+// visibleSpans returns the spans of the visible cells of a row from the spans
+// of its cells by written index: each spanning cell spans the visible columns
+// among those it spans as written.
+func (w *Writer) visibleSpans(spans []int, nCells int) []int {
+	if spans == nil {
+		return nil
+	}
+	visible := make([]int, nCells) // visible index of each written cell, or -1
+	var n int
+	for i := range visible {
+		visible[i] = -1
+		if !w.isOmitted(i) {
+			visible[i] = n
+			n++
+		}
+	}
+	result := make([]int, n)
+	for i, span := range spans {
+		if span < 2 || visible[i] == -1 {
+			continue
+		}
+		for k := i; k < i+span && k < nCells; k++ {
+			if visible[k] != -1 {
+				result[visible[i]]++
+			}
+		}
+	}
+	return result
+}
+
+// span returns the number of columns spanned by the cell of the given index:
+// 1 for a regular cell, more for a spanning cell, and 0 for a cell covered by
+// a spanning cell on its left.
+func (r row) span(colIdx int) int {
+	for ci := 0; ci < colIdx && ci < len(r.spans); ci++ {
+		if ci+r.spans[ci] > colIdx {
+			return 0
+		}
+	}
+	if colIdx < len(r.spans) && r.spans[colIdx] > 1 {
+		return r.spans[colIdx]
+	}
+	return 1
+}
+
+// joined returns, for each boundary between two of the n columns (the index
+// of a boundary being the one of the column on its right, as for
+// ColumnSeparator), whether it is inside a cell spanning several columns, or
+// nil if no cell does.
+func (r row) joined(n int) []bool {
+	var joined []bool
+	for ci, span := range r.spans {
+		for b := ci + 1; b < ci+span && b < n; b++ {
+			if joined == nil {
+				joined = make([]bool, n)
+			}
+			joined[b] = true
+		}
+	}
+	return joined
+}
+
+// selectSpans returns the spans of the cells of a row once its columns are
+// selected, its new column j being its column cols[j]; the spans are cut
+// where the columns are no longer adjacent.
+func selectSpans(spans []int, cols []int) []int {
+	if spans == nil {
+		return nil
+	}
+	selected := make([]int, len(cols))
+	for j, ci := range cols {
+		if ci >= len(spans) || spans[ci] < 2 {
+			continue
+		}
+		n := 1
+		for n < spans[ci] && j+n < len(cols) && cols[j+n] == ci+n {
+			n++
+		}
+		selected[j] = n
+	}
+	return selected
+}
+
+// dropSpan adjusts the spans of the cells of a row before its column of the
+// given index is dropped: the cells spanning it span one column less, and a
+// spanning cell starting there moves to the next column.
+func dropSpan(r row, drop int) row {
+	if r.spans == nil {
+		return r
+	}
+	spans := append([]int(nil), r.spans...)
+	for ci := 0; ci < drop && ci < len(spans); ci++ {
+		if ci+spans[ci] > drop {
+			spans[ci]--
+		}
+	}
+	if drop+1 < len(spans) && spans[drop] > 1 && drop+1 < len(r.cells) {
+		cells := append([]string(nil), r.cells...)
+		cells[drop+1] = cells[drop]
+		r.cells = cells
+		if drop < len(r.opts) {
+			opts := make([]cellOpts, len(r.cells))
+			copy(opts, r.opts)
+			opts[drop+1] = opts[drop]
+			r.opts = opts
+		}
+		spans[drop+1] = spans[drop] - 1
+	}
+	if drop < len(spans) {
+		spans = append(spans[:drop:drop], spans[drop+1:]...)
+	}
+	r.spans = spans
+	return r
+}
+
+// spanWidth returns the width of a cell spanning the columns from first to
+// last, included: the widths of the columns, along with their padding and
+// the column separators in between.
+func spanWidth(l layout, first, last int) int {
+	width := l.widths[first]
+	for ci := first + 1; ci <= last; ci++ {
+		width += l.pads[ci-1] + textutil.Width(l.deco.ColumnSeparator(0, ci)) + l.widths[ci]
+	}
+	return width
+}
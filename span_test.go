@@ -0,0 +1,58 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpan(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{}, Rigid{})
+	writer.SetHeaders("name", "qty", "price")
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteRow(Span(3, "fruits"))
+	writer.WriteRow("pear", 3, 1.5)
+	writer.WriteRow(Span(2, "total"), 9)
+	writer.WriteRow(Span(3, "no results"))
+	writer.Flush()
+
+	expected := "" +
+		"+------+-----+-------+\n" +
+		"| name | qty | price |\n" +
+		"+======+=====+=======+\n" +
+		"| fruits             |\n" +
+		"+------+-----+-------+\n" +
+		"| pear | 3   | 1.5   |\n" +
+		"+------+-----+-------+\n" +
+		"| total      | 9     |\n" +
+		"+------------+-------+\n" +
+		"| no results         |\n" +
+		"+--------------------+\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestSpanOmittedColumn(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Omit{}, Rigid{}, Rigid{})
+
+	writer.WriteRow("a", "b", "c", "d")
+	writer.WriteRow("e", Span(2, "wide"), "f")
+	writer.Flush()
+
+	expected := "" +
+		"+---+---+---+\n" +
+		"| a | c | d |\n" +
+		"+---+---+---+\n" +
+		"| e |   | f |\n" +
+		"+---+---+---+\n"
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,89 @@
+package flexwriter
+
+// SetStreaming enables the streaming mode if sample is positive, or disables
+// it if it is 0, which is the default. In this mode, the widths of the columns
+// are computed from the first sample rows, which are written as soon as they
+// are all buffered; the widths are then frozen, and each row written after
+// that is written to the output immediately, so that e.g. millions of rows
+// can be written with a bounded memory. The rows that don't fit the frozen
+// widths are wrapped or truncated like in any other flush.
+//
+// [Writer.Flush] ends the stream: it writes the remaining rows, the bottom
+// border and the caption below the table, if any, and the next rows start a
+// new table. If writing the rows fails in between, the error is returned by
+// that final flush, and the rows written after the failure are kept in the
+// buffer.
+//
+// As the rows are written as they come, the rows are only sorted, ordered by
+// sequence numbers or laid out as a tree within the sample, or within each
+// call writing several rows. The streaming mode is ignored in band mode and in
+// live mode.
+func (w *Writer) SetStreaming(sample int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.streamSample = sample
+}
+
+// SetMaxBufferedRows sets the maximum number of rows kept in the buffer, to
+// bound the memory used when writing e.g. an unbounded stream of rows: once n
+// rows are buffered, they are written to the output, as by an automatic
+// flush. The widths of the columns are computed from the first n rows, and
+// they are kept for the next rows, so that the columns stay aligned from one
+// chunk to the next, as in streaming mode (see [Writer.SetStreaming]), which
+// also applies to the final [Writer.Flush] and to the write errors. If n is
+// 0, which is the default, the number of buffered rows is not limited.
+func (w *Writer) SetMaxBufferedRows(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.maxBuffered = n
+}
+
+// stream is the state of a stream in progress, see SetStreaming.
+type stream struct {
+	widths  []int   // widths of the columns, frozen once the sample is written
+	outer   []int   // outer widths of the columns, for the bottom border
+	rows    int     // number of rows written
+	kind    RowKind // kind of the last row written
+	joined  []bool  // boundaries joined by spanning cells in the last row written
+	stripes int     // number of body rows written, see SetAlternatingRows
+	err     error   // error of a write, returned by the final flush
+}
+
+// streamRows writes the buffered rows in streaming mode, once the sample is
+// complete, or once the maximum number of buffered rows is reached.
+func (w *Writer) streamRows() {
+	if (w.streamSample <= 0 && w.maxBuffered <= 0) || w.bands || w.live {
+		return
+	}
+	if w.stream == nil {
+		w.stream = &stream{}
+	}
+	if w.stream.err != nil {
+		return
+	}
+	// once the widths are frozen, the rows are streamed one at a time
+	n := 1
+	if w.stream.widths == nil {
+		n = w.streamSample
+	}
+	if w.streamSample <= 0 || (w.maxBuffered > 0 && w.maxBuffered < n) {
+		n = w.maxBuffered
+	}
+	if len(w.rows) < n {
+		return
+	}
+
+	w.streaming = true
+	defer func() { w.streaming = false }()
+	if _, err := w.flush(); err != nil {
+		w.stream.err = err
+	}
+}
+
+// streamContinues returns whether the rows being flushed follow the rows
+// already written by a stream in progress.
+func (w *Writer) streamContinues() bool {
+	return w.stream != nil && w.stream.widths != nil
+}
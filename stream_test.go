@@ -0,0 +1,99 @@
+package flexwriter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetDecorator(AsciiTableDecorator())
+	writer.SetColumns(Rigid{}, Rigid{Align: Right})
+	writer.SetHeaders("name", "qty")
+	writer.SetHeaderStyle(nil)
+	writer.SetCaption(Caption{Text: "fruits", Below: true})
+	writer.SetStreaming(2)
+
+	writer.WriteRow("pear", 3)
+	assert.Equal(t, "", buf.String())
+	writer.WriteRow("fig", 12)
+	sample := "" +
+		"+------+-----+\n" +
+		"| name | qty |\n" +
+		"+======+=====+\n" +
+		"| pear |   3 |\n" +
+		"+------+-----+\n" +
+		"| fig  |  12 |\n"
+	assert.Equal(t, sample, buf.String())
+
+	// the widths are frozen, the wider cells are wrapped
+	writer.WriteRow("apple", 1)
+	assert.Equal(t, sample+""+
+		"+------+-----+\n"+
+		"| appl |   1 |\n"+
+		"| e    |     |\n", buf.String())
+
+	writer.Flush()
+	assert.Equal(t, sample+""+
+		"+------+-----+\n"+
+		"| appl |   1 |\n"+
+		"| e    |     |\n"+
+		"+------+-----+\n"+
+		"fruits\n", buf.String())
+
+	// the flush ended the stream, the next rows are a new table
+	buf.Reset()
+	writer.WriteRow("kiwi", 5)
+	writer.Flush()
+	assert.Equal(t, ""+
+		"+------+-----+\n"+
+		"| name | qty |\n"+
+		"+======+=====+\n"+
+		"| kiwi |   5 |\n"+
+		"+------+-----+\n"+
+		"fruits\n", buf.String())
+}
+
+func TestStreamingError(t *testing.T) {
+	out := &shortWriter{size: 100, limit: 0}
+	writer := New()
+	writer.SetOutput(out)
+	writer.SetStreaming(1)
+
+	writer.WriteRow("a")
+	out.limit = 100
+	writer.WriteRow("b")
+	assert.Equal(t, "", out.String())
+
+	// the error is returned by the final flush, the rows are kept
+	err := writer.Flush()
+	assert.Equal(t, &WriteError{Kept: true, Err: io.ErrClosedPipe}, err)
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "a\nb\n", out.String())
+}
+
+func TestMaxBufferedRows(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetMaxBufferedRows(2)
+
+	writer.WriteRow("a", "bb")
+	assert.Equal(t, 0, len(buf.String()))
+	writer.WriteRow("ccc", "d")
+	assert.Equal(t, "a    bb\nccc  d\n", buf.String())
+	assert.Equal(t, 0, writer.Stats().BufferedRows)
+
+	// the next chunk keeps the widths of the first one
+	writer.WriteRow("e", "f")
+	assert.Equal(t, 1, writer.Stats().BufferedRows)
+	writer.WriteRows([][]any{{"g", "h"}, {"i", "j"}})
+	assert.Equal(t, "a    bb\nccc  d\ne    f\ng    h\ni    j\n", buf.String())
+	writer.Flush()
+	assert.Equal(t, "a    bb\nccc  d\ne    f\ng    h\ni    j\n", buf.String())
+}
@@ -0,0 +1,231 @@
+package flexwriter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WriteStruct writes a row with the values of the exported fields of v, a
+// struct or a pointer to a struct, like [Writer.WriteRow]. The first time a
+// struct of a given type is written, the columns and the header row are set
+// from the fields, as with [Writer.SetColumns] and [Writer.SetHeaders], so
+// they can be adjusted after that first call.
+//
+// By default, each field is a [Shrinkable] column whose header is the name
+// of the field; this can be changed with a "flex" struct tag made of the
+// header, possibly empty, followed by comma-separated options, e.g.
+// `flex:"Size,align=right,max=20"`:
+//   - align=left, align=center or align=right sets the alignment
+//   - min=N and max=N set the min and max widths
+//   - wrap=word, wrap=char or wrap=none sets the [WrapMode]
+//   - rigid or flexed makes the column a [Rigid] or a [Flexed] one
+//
+// Unknown options are ignored. A field with the tag `flex:"-"` is skipped,
+// and the fields of an embedded struct without a tag are written as if they
+// were fields of v. A nil pointer to a struct is written as an empty row.
+// WriteStruct panics if v is not a struct or a pointer to a struct.
+func (w *Writer) WriteStruct(v any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeStruct(v)
+	w.streamRows()
+}
+
+// WriteStructsWithHeader writes a row for each element of structs, a slice
+// of structs or of pointers to structs, as with [Writer.WriteStruct]; a nil
+// pointer is written as an empty row. It also sets the header row from the
+// fields, as with [Writer.SetHeaders], even if the structs are of the type
+// of the last struct written. WriteStructsWithHeader panics if structs is not
+// a slice of structs or of pointers to structs.
+func (w *Writer) WriteStructsWithHeader(structs any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rv := reflect.ValueOf(structs)
+	if rv.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("flexwriter: WriteStructsWithHeader of a non-slice %T", structs))
+	}
+	t := rv.Type().Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("flexwriter: WriteStructsWithHeader of a non-struct slice %T", structs))
+	}
+
+	if t != w.structType {
+		w.setStructType(t)
+	}
+	w.headers = transform(structFields(t), func(f structField) any { return f.header })
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i)
+		for elem.Kind() == reflect.Pointer && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			w.writeRow()
+			continue
+		}
+		w.writeStruct(elem.Interface())
+	}
+	w.streamRows()
+}
+
+// FromStructs returns a new flex writer, as returned by [New], with the
+// columns and the header row set from the type T, and the rows of the given
+// structs written, as with [Writer.WriteStruct]; it only remains to flush it.
+func FromStructs[T any](rows []T) *Writer {
+	writer := New()
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	// the columns are set even if there are no rows
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		writer.setStructType(t)
+	}
+	for _, row := range rows {
+		writer.writeStruct(row)
+	}
+	return writer
+}
+
+func (w *Writer) writeStruct(v any) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Pointer && rv.Type().Elem().Kind() == reflect.Struct {
+		if rv.Type().Elem() != w.structType {
+			w.setStructType(rv.Type().Elem())
+		}
+		w.writeRow()
+		return
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("flexwriter: WriteStruct of a non-struct %T", v))
+	}
+
+	fields := structFields(rv.Type())
+	if rv.Type() != w.structType {
+		w.setStructType(rv.Type())
+	}
+
+	cells := make([]any, len(fields))
+	for i, f := range fields {
+		cells[i] = rv.FieldByIndex(f.index).Interface()
+	}
+	w.writeRow(cells...)
+}
+
+// setStructType sets the columns and the header row from the fields of the
+// struct type t.
+func (w *Writer) setStructType(t reflect.Type) {
+	fields := structFields(t)
+	w.structType = t
+	w.colDefs = transform(fields, func(f structField) Column { return f.column })
+	w.applyColumns()
+	w.headers = transform(fields, func(f structField) any { return f.header })
+}
+
+// structField is a field of a struct written as a column.
+type structField struct {
+	index  []int // index of the field, for reflect.Value.FieldByIndex
+	header string
+	column Column
+}
+
+// structFieldsCache caches the fields of the struct types, by type.
+var structFieldsCache sync.Map
+
+// structFields returns the fields of the struct type t that are written as
+// columns, in order.
+func structFields(t reflect.Type) []structField {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("flex")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			for _, embedded := range structFields(f.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		header, column := parseStructTag(f.Name, tag)
+		fields = append(fields, structField{index: []int{i}, header: header, column: column})
+	}
+
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// parseStructTag returns the header and the column configuration of a field
+// from its "flex" struct tag.
+func parseStructTag(name, tag string) (string, Column) {
+	opts := strings.Split(tag, ",")
+	header := opts[0]
+	if header == "" {
+		header = name
+	}
+
+	var kind string
+	var minWidth, maxWidth int
+	var align Alignment
+	var wrap WrapMode
+	for _, opt := range opts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "rigid", "flexed":
+			kind = key
+		case "align":
+			switch value {
+			case "left":
+				align = Left
+			case "center":
+				align = Center
+			case "right":
+				align = Right
+			}
+		case "min":
+			minWidth, _ = strconv.Atoi(value)
+		case "max":
+			maxWidth, _ = strconv.Atoi(value)
+		case "wrap":
+			switch value {
+			case "word":
+				wrap = WrapWord
+			case "char":
+				wrap = WrapChar
+			case "none":
+				wrap = WrapNone
+			}
+		}
+	}
+
+	switch kind {
+	case "rigid":
+		return header, Rigid{Min: minWidth, Max: maxWidth, Align: align, Wrap: wrap}
+	case "flexed":
+		return header, Flexed{Min: minWidth, Max: maxWidth, Align: align, Wrap: wrap}
+	default:
+		return header, Shrinkable{Min: minWidth, Max: maxWidth, Align: align, Wrap: wrap}
+	}
+}
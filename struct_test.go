@@ -0,0 +1,94 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type base struct {
+	ID int `flex:"#,align=right"`
+}
+
+type fruit struct {
+	base
+	Name   string
+	Color  string  `flex:",wrap=none,max=5"`
+	Price  float64 `flex:"Price (€),rigid,align=right"`
+	Secret string  `flex:"-"`
+	note   string
+}
+
+func TestWriteStruct(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteStruct(fruit{base: base{ID: 1}, Name: "apple", Color: "red", Price: 1.5})
+	writer.WriteStruct((*fruit)(nil))
+	writer.WriteStruct(&fruit{base: base{ID: 12}, Name: "banana", Color: "yellow", Price: 0.25, Secret: "x"})
+	writer.Flush()
+
+	// a nil pointer is written as an empty row
+	assert.Equal(t, ""+
+		" #  Name    Color  Price (€)\n"+
+		" 1  apple   red          1.5\n"+
+		"                            \n"+
+		"12  banana  yello       0.25\n", buf.String())
+
+	assert.Panics(t, func() { writer.WriteStruct(42) })
+	assert.Panics(t, func() { writer.WriteStruct((*int)(nil)) })
+}
+
+func TestFromStructs(t *testing.T) {
+	var buf bytes.Buffer
+	writer := FromStructs([]base{{ID: 1}, {ID: 2}})
+	writer.SetOutput(&buf)
+	writer.SetHeaderStyle(nil)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"#\n"+
+		"1\n"+
+		"2\n", buf.String())
+}
+
+func TestWriteStructsWithHeader(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetHeaderStyle(nil)
+
+	writer.WriteStructsWithHeader([]*fruit{
+		{base: base{ID: 1}, Name: "apple", Color: "red", Price: 1.5},
+		nil,
+		{base: base{ID: 12}, Name: "banana", Color: "yellow", Price: 0.25, Secret: "x"},
+	})
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		" #  Name    Color  Price (€)\n"+
+		" 1  apple   red          1.5\n"+
+		"                            \n"+
+		"12  banana  yello       0.25\n", buf.String())
+
+	assert.Panics(t, func() { writer.WriteStructsWithHeader(fruit{}) })
+	assert.Panics(t, func() { writer.WriteStructsWithHeader([]int{42}) })
+}
+
+func TestFromStructsEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writer := FromStructs([]*fruit{})
+	writer.SetOutput(&buf)
+	writer.SetHeaderStyle(nil)
+
+	// the columns are set from the type
+	writer.WriteRow(3, "kiwi", "green", 2.5)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"#  Name  Color  Price (€)\n"+
+		"3  kiwi  green        2.5\n", buf.String())
+}
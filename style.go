@@ -0,0 +1,115 @@
+package flexwriter
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// style is a text style, stored as the escape sequences that start and end it
+// so that it can be applied with simple concatenation.
+type style struct {
+	in  string
+	out string
+}
+
+// newStyle extracts the escape sequences of a color; a nil color is the empty
+// style.
+func newStyle(c *color.Color) style {
+	if c == nil {
+		return style{}
+	}
+	// fatih/color is very badly designed and is extremely inefficient, but we
+	// can improve the situation by first making it colorize a string, use it
+	// to extract the in and out escape strings, and then use those with simple
+	// concatenation.
+	// the colors are disabled when flushing if needed, see SetColorMode
+	enabled := *c
+	enabled.EnableColor()
+	cut := "__CUT_HERE__"
+	colored := enabled.Sprint(cut)
+	in, out, _ := strings.Cut(colored, cut)
+	return style{in: in, out: out}
+}
+
+// apply styles the string; an empty string stays empty.
+func (s style) apply(str string) string {
+	if str == "" {
+		return ""
+	}
+	return s.in + str + s.out
+}
+
+// isZero returns whether the style does nothing.
+func (s style) isZero() bool {
+	return s.in == "" && s.out == ""
+}
+
+// SetAlternatingColumns tints the columns alternately with the first and the
+// second color (the first, third, etc. columns with first; the second, fourth,
+// etc. with second), which helps following the columns of very wide tables.
+// The content of the columns is tinted along with their padding, but not the
+// column separators; use [ColorizeDecorator] for those. A nil color leaves
+// the corresponding columns untouched; calling SetAlternatingColumns(nil, nil)
+// removes the tints.
+//
+// Note that a style reset within the content of a cell also ends the tint.
+func (w *Writer) SetAlternatingColumns(first, second *color.Color) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.colStyles = []style{newStyle(first), newStyle(second)}
+}
+
+// SetAlternatingRows tints the body rows alternately with the first and the
+// second color (zebra striping), which helps following the rows of wide
+// tables. As with [Writer.SetAlternatingColumns], the content of the cells is
+// tinted along with their padding, but not the column separators. The header
+// and section rows are not tinted and don't count in the alternation. The
+// tints of the rows take precedence over those of the columns, and the colors
+// set with [Writer.SetRowStyle] over both. A nil color leaves the
+// corresponding rows untouched; calling SetAlternatingRows(nil, nil) removes
+// the tints.
+func (w *Writer) SetAlternatingRows(first, second *color.Color) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rowStyles = []style{newStyle(first), newStyle(second)}
+}
+
+// columnStyle returns the style of the visible column of the given index.
+func (w *Writer) columnStyle(colIdx int) style {
+	if len(w.colStyles) == 0 {
+		return style{}
+	}
+	return w.colStyles[colIdx%len(w.colStyles)]
+}
+
+// cellStyle returns the style of the content of a cell: its own style, or the
+// style of its column if it has none and doesn't span several columns.
+func (w *Writer) cellStyle(r row, colIdx int) style {
+	if w.noColors {
+		return style{}
+	}
+	if s := r.cellOpts(colIdx).style; !s.isZero() || r.span(colIdx) != 1 {
+		return s
+	}
+	return w.getColumnDef(colIdx).style
+}
+
+// tint returns the style of a cell from its row or its column, the style of
+// the row taking precedence.
+func (w *Writer) tint(r row, colIdx int) style {
+	if w.noColors {
+		return style{}
+	}
+	if !r.style.isZero() {
+		return r.style
+	}
+	if len(w.rowStyles) > 0 && r.stripe > 0 {
+		if s := w.rowStyles[(r.stripe-1)%len(w.rowStyles)]; !s.isZero() {
+			return s
+		}
+	}
+	return w.columnStyle(colIdx)
+}
@@ -0,0 +1,668 @@
+// Package textutil provides helpers to wrap, align, truncate and measure text
+// for terminal output. They are the ones used by flexwriter, and as such they
+// correctly handle ANSI escape sequences (e.g. color codes) and wide characters.
+package textutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	text "github.com/MichaelMure/go-term-text"
+	"github.com/mattn/go-runewidth"
+)
+
+// Width returns the display width of s, i.e. the number of terminal cells it
+// takes: escape sequences don't take any space, and wide characters (e.g. CJK
+// characters) take two cells. If s has several lines, this is the width of
+// the widest one.
+func Width(s string) int {
+	s, _ = protectOSC(s)
+	if !strings.Contains(s, "\n") {
+		return text.Len(s)
+	}
+	var width int
+	for _, line := range strings.Split(s, "\n") {
+		if l := text.Len(line); l > width {
+			width = l
+		}
+	}
+	return width
+}
+
+// Wrap wraps s into lines no wider than width, breaking between words if
+// possible; words can also be broken after a hyphen between two letters or
+// digits, e.g. in "well-known". Styles set by escape sequences are reset at
+// the end of each line and restored at the start of the next one, so that each
+// line can be printed independently. The zero width spaces (U+200B), e.g.
+// added by [MarkBreaks], are break opportunities and are removed from the
+// lines. It returns an error if width is not positive.
+func Wrap(s string, width int) ([]string, error) {
+	return WrapMarked(s, width, "")
+}
+
+// WrapMarked is like [Wrap], but the lines after the first one are prefixed
+// with the marker, e.g. "↪ ", so that they can be told apart from separate
+// lines; the marker counts in the width of the lines. If the marker is not
+// narrower than width, it is not used.
+func WrapMarked(s string, width int, marker string) ([]string, error) {
+	return WrapIndent(s, width, "", marker)
+}
+
+// WrapIndent is like [Wrap], but the first line is prefixed with indent, and
+// the next ones with pad; they count in the width of the lines. An indent or a
+// pad that is not narrower than width is not used.
+func WrapIndent(s string, width int, indent, pad string) ([]string, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("textutil: width must be > 0, got %d", width)
+	}
+
+	if Width(indent) >= width {
+		indent = ""
+	}
+	if Width(pad) >= width {
+		pad = ""
+	}
+	s, seqs := protectOSC(s)
+
+	// strangely, text.Wrap doesn't return early if there is no need to wrap,
+	// and is quite inefficient to "wrap" something that doesn't need to be;
+	// so we check ourselves
+	if Width(indent)+Width(s) <= width && !strings.Contains(s, "\n") {
+		return []string{indent + restoreOSC(strings.ReplaceAll(s, zeroWidthSpace, ""), seqs)}, nil
+	}
+
+	s = markHyphens(s)
+	if indent == "" {
+		// an empty indent defaults to the pad, so the first line is indented
+		// with an invisible zero width space instead
+		indent = zeroWidthSpace
+	}
+	wrapped, _ := text.Wrap(s, width, text.WrapIndent(indent), text.WrapPad(pad))
+	lines := strings.Split(wrapped, "\n")
+
+	var state text.EscapeState
+	var link string // hyperlink continued on the next line
+	for i, line := range lines {
+		line = state.FormatString() + line
+		state.Witness(line)
+		line = line + state.ResetString()
+		line = strings.ReplaceAll(line, zeroWidthSpace, "")
+		if seqs != nil {
+			line = link + restoreOSC(line, seqs)
+			if link = openLink(line); link != "" {
+				line += closeLink
+			}
+		}
+		lines[i] = line
+	}
+
+	return lines, nil
+}
+
+// Truncate cuts s so that it is no wider than width, in which case the end is
+// replaced by the marker, e.g. "…". Escape sequences are kept, and any style
+// still active at the cut is reset so that it doesn't bleed into the marker.
+func Truncate(s string, width int, marker string) string {
+	if Width(s) <= width {
+		return s
+	}
+	markerLen := Width(marker)
+	if markerLen > width {
+		marker = ""
+		markerLen = 0
+	}
+	limit := width - markerLen
+
+	s, seqs := protectOSC(s)
+	var sb strings.Builder
+	var n int
+	escape := false
+	for _, r := range s {
+		if r == '\x1b' {
+			escape = true
+		}
+		if escape {
+			sb.WriteRune(r)
+			if r == 'm' {
+				escape = false
+			}
+			continue
+		}
+		rw := runewidth.RuneWidth(r)
+		if n+rw > limit {
+			break
+		}
+		sb.WriteRune(r)
+		n += rw
+	}
+
+	var state text.EscapeState
+	state.Witness(sb.String())
+	cut := restoreOSC(sb.String(), seqs)
+	sb.Reset()
+	sb.WriteString(cut)
+	if openLink(cut) != "" {
+		sb.WriteString(closeLink)
+	}
+	if !state.IsZero() {
+		sb.WriteString(state.ResetString())
+	}
+	// a wide character may not have fit in the last cell
+	sb.WriteString(strings.Repeat(" ", limit-n))
+	sb.WriteString(marker)
+	return sb.String()
+}
+
+// zeroWidthSpace is invisible, and being in a category of its own it is a break
+// opportunity for the wrapping.
+const zeroWidthSpace = "\u200b"
+
+// markHyphens marks the hyphens between two letters or digits as break
+// opportunities, see [MarkBreaks].
+func markHyphens(s string) string {
+	if !strings.Contains(s, "-") {
+		return s
+	}
+	s, seqs := protectOSC(s)
+	var sb strings.Builder
+	// the last two visible runes
+	var prev, prevPrev rune
+	escape := false
+	for _, r := range s {
+		if r == '\x1b' {
+			escape = true
+		}
+		if escape {
+			sb.WriteRune(r)
+			if r == 'm' {
+				escape = false
+			}
+			continue
+		}
+		if prev == '-' && isAlnum(prevPrev) && isAlnum(r) {
+			sb.WriteString(zeroWidthSpace)
+		}
+		sb.WriteRune(r)
+		prevPrev, prev = prev, r
+	}
+	return restoreOSC(sb.String(), seqs)
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// MarkBreaks adds break opportunities to s, after each character for which
+// breakAfter returns true, by inserting zero width spaces. They are taken into
+// account by [Wrap] and [MinContent], and as they take no space, they don't
+// change the width of s. If breakAfter is nil, s is returned unchanged.
+func MarkBreaks(s string, breakAfter func(r rune) bool) string {
+	if breakAfter == nil {
+		return s
+	}
+	s, seqs := protectOSC(s)
+	var sb strings.Builder
+	escape := false
+	for _, r := range s {
+		sb.WriteRune(r)
+		if r == '\x1b' {
+			escape = true
+		}
+		if escape {
+			if r == 'm' {
+				escape = false
+			}
+			continue
+		}
+		if breakAfter(r) {
+			sb.WriteString(zeroWidthSpace)
+		}
+	}
+	return restoreOSC(sb.String(), seqs)
+}
+
+// Hyphenate breaks the words of s that are wider than width, inserting a
+// hyphen and a line break at each break, so that [Wrap] doesn't cut them
+// anywhere. Each fragment, including its
+// hyphen, fits in width and is at least minFragment characters long; words
+// shorter than 2*minFragment are not broken, and neither is anything if width
+// is too small for such fragments. Wide characters and spaces are never part
+// of a word. If minFragment is not positive, s is returned unchanged.
+func Hyphenate(s string, width, minFragment int) string {
+	if minFragment <= 0 || width-1 < minFragment || Width(s) <= width {
+		return s
+	}
+
+	s, seqs := protectOSC(s)
+	stripped, escapes := text.ExtractTermEscapes(s)
+	runes := []rune(stripped)
+
+	// breaks are the indexes of the runes before which "-\n" is inserted
+	var breaks []int
+	for start := 0; start < len(runes); {
+		if !hyphenable(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && hyphenable(runes[end]) {
+			end++
+		}
+		n := end - start
+		if n >= 2*minFragment {
+			for n > width {
+				take := width - 1
+				if n-take < minFragment {
+					// leave enough for the last fragment
+					take = n - minFragment
+				}
+				start += take
+				n -= take
+				breaks = append(breaks, start)
+			}
+		}
+		start = end
+	}
+	if len(breaks) == 0 {
+		return restoreOSC(s, seqs)
+	}
+
+	var sb strings.Builder
+	var bi int
+	for i, r := range runes {
+		if bi < len(breaks) && breaks[bi] == i {
+			sb.WriteString("-\n")
+			bi++
+		}
+		sb.WriteRune(r)
+	}
+	bi = 0
+	for i := range escapes {
+		for bi < len(breaks) && breaks[bi] <= escapes[i].Pos {
+			bi++
+		}
+		escapes[i].Pos += 2 * bi
+	}
+	return restoreOSC(text.ApplyTermEscapes(sb.String(), escapes), seqs)
+}
+
+// HyphenatedMinContent is like [MinContent], but for text that is hyphenated
+// with [Hyphenate] with the given minFragment: the words that can be broken
+// only need room for a fragment and its hyphen.
+func HyphenatedMinContent(s string, minFragment int) int {
+	if minFragment <= 0 {
+		return MinContent(s)
+	}
+	s, _ = protectOSC(s)
+	escaped, _ := text.ExtractTermEscapes(markHyphens(s))
+
+	var max, word int
+	flushWord := func() {
+		if word >= 2*minFragment && word > minFragment+1 {
+			word = minFragment + 1
+		}
+		if word > max {
+			max = word
+		}
+		word = 0
+	}
+	for _, r := range escaped {
+		if hyphenable(r) {
+			word++
+			continue
+		}
+		flushWord()
+		if rw := runewidth.RuneWidth(r); rw > max && r != ' ' {
+			max = rw
+		}
+	}
+	flushWord()
+	return max
+}
+
+// hyphenable returns whether r can be part of a word broken by Hyphenate.
+func hyphenable(r rune) bool {
+	t, _ := runeTypeOf(r)
+	return t == visibleAscii || t == shortUnicode
+}
+
+// CloseStyles appends a reset sequence to s if it leaves a style active, e.g.
+// a color that is never reset, so that the style doesn't bleed into what is
+// printed after s.
+func CloseStyles(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	if openLink(s) != "" {
+		s += closeLink
+	}
+	protected, _ := protectOSC(s)
+	var state text.EscapeState
+	state.Witness(protected)
+	if state.IsZero() {
+		return s
+	}
+	return s + state.ResetString()
+}
+
+// StripEscapes removes all the escape sequences from s: the CSI sequences,
+// e.g. colors, the OSC sequences, e.g. hyperlinks, and the other two-character
+// sequences. An unterminated sequence is removed up to the end of s.
+func StripEscapes(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '\x1b' {
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		i += escapeLen(s[i:])
+	}
+	return sb.String()
+}
+
+// escapeLen returns the length of the escape sequence at the start of s.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		// CSI: parameters and intermediate bytes, then a final byte
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		// OSC: terminated by BEL or ST (ESC \)
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
+
+// The go-term-text package only knows the escape sequences that end with an
+// "m", e.g. the colors, but not the OSC sequences, e.g. the OSC 8 hyperlinks,
+// whose URLs may contain an "m"; they are replaced by placeholders that it
+// handles, "\x1b[?Nm" for the Nth sequence, which are ignored as styles.
+
+// protectOSC replaces the OSC sequences of s by placeholders, and returns
+// the sequences.
+func protectOSC(s string) (string, []string) {
+	if !strings.Contains(s, "\x1b]") {
+		return s, nil
+	}
+	var sb strings.Builder
+	var seqs []string
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == ']' {
+			n := escapeLen(s[i:])
+			fmt.Fprintf(&sb, "\x1b[?%dm", len(seqs))
+			seqs = append(seqs, s[i:i+n])
+			i += n
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String(), seqs
+}
+
+// restoreOSC replaces the placeholders of protectOSC by their sequences.
+func restoreOSC(s string, seqs []string) string {
+	if seqs == nil {
+		return s
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "\x1b[?")
+		if i == -1 {
+			break
+		}
+		end := strings.IndexByte(s[i:], 'm')
+		if end == -1 {
+			break
+		}
+		n, err := strconv.Atoi(s[i+3 : i+end])
+		if err != nil || n < 0 || n >= len(seqs) {
+			sb.WriteString(s[:i+3])
+			s = s[i+3:]
+			continue
+		}
+		sb.WriteString(s[:i])
+		sb.WriteString(seqs[n])
+		s = s[i+end+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+// closeLink is the OSC 8 sequence that ends a hyperlink.
+const closeLink = "\x1b]8;;\x1b\\"
+
+// openLink returns the OSC 8 sequence of the hyperlink that is left open at
+// the end of s, if any.
+func openLink(s string) string {
+	var open string
+	for {
+		i := strings.Index(s, "\x1b]8;")
+		if i == -1 {
+			return open
+		}
+		n := escapeLen(s[i:])
+		seq := s[i : i+n]
+		s = s[i+n:]
+		// the sequence is "\x1b]8;params;URI" and its terminator, with an
+		// empty URI to end the hyperlink
+		body := strings.TrimSuffix(strings.TrimSuffix(seq, "\x1b\\"), "\a")
+		open = ""
+		if j := strings.IndexByte(body[4:], ';'); j != -1 && body[4+j+1:] != "" {
+			open = seq
+		}
+	}
+}
+
+// TrimRight removes the trailing whitespace of s; its escape sequences are
+// kept, even those after the removed whitespace, so that e.g. a style is still
+// reset at the end of s.
+func TrimRight(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return strings.TrimRightFunc(s, unicode.IsSpace)
+	}
+	s, seqs := protectOSC(s)
+	stripped, escapes := text.ExtractTermEscapes(s)
+	return restoreOSC(text.ApplyTermEscapes(strings.TrimRightFunc(stripped, unicode.IsSpace), escapes), seqs)
+}
+
+// Highlight wraps the matches of re in s, ignoring its escape sequences, with
+// the in and out sequences. After each match, the style that s itself had at
+// the end of the match is restored, so out should reset the style entirely.
+// As the escapes are carried over by [Wrap], a match that is wrapped over
+// several lines is highlighted on all of them.
+func Highlight(s string, re *regexp.Regexp, in, out string) string {
+	protected, seqs := protectOSC(s)
+	stripped, escapes := text.ExtractTermEscapes(protected)
+	matches := re.FindAllStringIndex(stripped, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	// the escapes are positioned by rune indexes
+	runeIdx := func(byteIdx int) int {
+		return len([]rune(stripped[:byteIdx]))
+	}
+	var highlighted []text.EscapeItem
+	var state text.EscapeState
+	var ei int
+	witness := func(pos int) {
+		for ei < len(escapes) && escapes[ei].Pos <= pos {
+			highlighted = append(highlighted, escapes[ei])
+			state.Witness(escapes[ei].Item)
+			ei++
+		}
+	}
+	for _, m := range matches {
+		if m[0] == m[1] {
+			continue
+		}
+		start, end := runeIdx(m[0]), runeIdx(m[1])
+		witness(start)
+		highlighted = append(highlighted, text.EscapeItem{Item: in, Pos: start})
+		// the escapes within the match are kept, so that the state is right
+		// after it
+		witness(end)
+		highlighted = append(highlighted, text.EscapeItem{Item: out + state.FormatString(), Pos: end})
+	}
+	highlighted = append(highlighted, escapes[ei:]...)
+	return restoreOSC(text.ApplyTermEscapes(stripped, highlighted), seqs)
+}
+
+// Alignment is the horizontal alignment of text within a given width.
+type Alignment int
+
+const (
+	Left Alignment = iota
+	Center
+	Right
+)
+
+// figureSpace is a space as wide as a digit; it is not trimmed by Align, so
+// that it can be used to pad numbers, e.g. to align them on their last digit.
+const figureSpace = '\u2007'
+
+// trimSpace is like text.TrimSpace, but keeps the figure spaces.
+func trimSpace(s string) string {
+	s, seqs := protectOSC(s)
+	stripped, escapes := text.ExtractTermEscapes(s)
+	isSpace := func(r rune) bool {
+		return r != figureSpace && unicode.IsSpace(r)
+	}
+	trimmed := strings.TrimLeftFunc(stripped, isSpace)
+	left := utf8.RuneCountInString(stripped) - utf8.RuneCountInString(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, isSpace)
+	return restoreOSC(text.ApplyTermEscapes(trimmed, text.OffsetEscapes(escapes, -left)), seqs)
+}
+
+// Align trims the spaces around s, except the figure spaces (U+2007), and pads
+// it with spaces to the given width, according to the alignment. If padRight is false, no spaces are added after
+// the text, which is useful for the last column of a line. If s is wider than
+// width, it is returned trimmed but otherwise unchanged.
+func Align(s string, width int, align Alignment, padRight bool) string {
+	s = trimSpace(s)
+
+	padLen := width - Width(s)
+	if padLen <= 0 {
+		return s
+	}
+
+	switch align {
+	case Center:
+		padLeft := padLen / 2
+		padLen -= padLeft
+		s = strings.Repeat(" ", padLeft) + s
+	case Right:
+		return strings.Repeat(" ", padLen) + s
+	}
+	if !padRight {
+		return s
+	}
+	return s + strings.Repeat(" ", padLen)
+}
+
+// MinContent returns the "min content" width of s, i.e. the width of its
+// longest unbreakable chunk, which is the minimum width it can be wrapped to
+// without breaking words. As in [Wrap], the parts of hyphenated words are
+// separate chunks.
+func MinContent(s string) int {
+	// adapted from go-term-text.segmentLine
+	s, _ = protectOSC(s)
+	escaped, _ := text.ExtractTermEscapes(markHyphens(s))
+
+	var max int
+
+	var wordLen int
+	wordType := none
+	flushWord := func() {
+		if wordLen > max {
+			max = wordLen
+		}
+		wordLen = 0
+		wordType = none
+	}
+
+	for _, r := range escaped {
+		// A WIDE_CHAR itself constitutes a chunk.
+		thisType, rw := runeTypeOf(r)
+		if thisType == wideChar {
+			if wordType != none {
+				flushWord()
+			}
+			wordLen = rw
+			flushWord()
+			continue
+		}
+		// Other type of chunks starts with a char of that type, and ends with a
+		// char with different type or end of string.
+		if thisType != wordType {
+			if wordType != none {
+				flushWord()
+			}
+			wordLen = rw
+			wordType = thisType
+		} else {
+			wordLen += rw
+		}
+	}
+	if wordLen != 0 {
+		flushWord()
+	}
+
+	return max
+}
+
+type runeType int
+
+// Rune categories
+//
+// These categories are so defined that each category forms a non-breakable
+// chunk. It IS NOT the same as unicode code point categories.
+const (
+	none runeType = iota
+	wideChar
+	invisible
+	shortUnicode
+	space
+	visibleAscii
+)
+
+// Determine the category of a rune.
+func runeTypeOf(r rune) (runeType, int) {
+	rw := runewidth.RuneWidth(r)
+	if rw > 1 {
+		return wideChar, rw
+	} else if rw == 0 {
+		return invisible, rw
+	} else if r > 127 {
+		return shortUnicode, rw
+	} else if r == ' ' {
+		return space, rw
+	} else {
+		return visibleAscii, rw
+	}
+}
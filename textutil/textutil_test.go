@@ -0,0 +1,143 @@
+package textutil
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	lines, err := Wrap("abcdefgh", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc", "def", "gh"}, lines)
+
+	lines, _ = Wrap("a well-known --flag", 6)
+	assert.Equal(t, []string{"a", "well-", "known", "--flag"}, lines)
+
+	// the hyperlinks are ended at the end of the lines and continued on the
+	// next ones
+	lines, _ = Wrap("see \x1b]8;;https://example.com/more\x1b\\the manual\x1b]8;;\x1b\\ now", 8)
+	assert.Equal(t, []string{
+		"see \x1b]8;;https://example.com/more\x1b\\the\x1b]8;;\x1b\\",
+		"\x1b]8;;https://example.com/more\x1b\\manual\x1b]8;;\x1b\\",
+		"now",
+	}, lines)
+
+	_, err = Wrap("abcdefgh", 0)
+	assert.EqualError(t, err, "textutil: width must be > 0, got 0")
+}
+
+func TestMinContent(t *testing.T) {
+	assert.Equal(t, 0, MinContent(""))
+	assert.Equal(t, 1, MinContent("a b c d"))
+	assert.Equal(t, 28, MinContent("a long word in the English language is antidisestablishmentarianism"))
+	assert.Equal(t, 34, MinContent("supercalifragilisticexpialidocious is even longer"))
+	assert.Equal(t, 2, MinContent("私はフライドポテトです。"))
+	assert.Equal(t, 6, MinContent("私はフライドpotatoです。"))
+	assert.Equal(t, 6, MinContent("a well-known --flag"))
+	assert.Equal(t, 6, MinContent("state-of-the-art x-ray"))
+	assert.Equal(t, 4, MinContent("\x1b]8;;https://my-domain.com\x1b\\a link\x1b]8;;\x1b\\"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", Truncate("abc", 3, "…"))
+	assert.Equal(t, "ab…", Truncate("abcd", 3, "…"))
+	assert.Equal(t, "abc", Truncate("abcd", 3, ""))
+	assert.Equal(t, "abc", Truncate("abcd", 3, "[more]"))
+	assert.Equal(t, "\x1b[31mab\x1b[0m…", Truncate("\x1b[31mabcd\x1b[0m", 3, "…"))
+	assert.Equal(t, "私 ", Truncate("私は", 3, ""))
+	assert.Equal(t, "\x1b]8;;https://example.com\aab\x1b]8;;\x1b\\…", Truncate("\x1b]8;;https://example.com\aabcd\x1b]8;;\a", 3, "…"))
+}
+
+func TestWidth(t *testing.T) {
+	assert.Equal(t, 0, Width(""))
+	assert.Equal(t, 5, Width("hello"))
+	assert.Equal(t, 5, Width("\x1b[1mhello\x1b[0m"))
+	assert.Equal(t, 4, Width("私は"))
+	assert.Equal(t, 5, Width("abc\nabcde\n"))
+	assert.Equal(t, 4, Width("\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\"))
+}
+
+func TestHyphenate(t *testing.T) {
+	assert.Equal(t, "short words", Hyphenate("short words", 5, 2))
+	assert.Equal(t, "a hyphen-\nation", Hyphenate("a hyphenation", 7, 2))
+	assert.Equal(t, "abcd-\nefg", Hyphenate("abcdefg", 6, 3))
+	assert.Equal(t, "abcdefg", Hyphenate("abcdefg", 6, 4))
+	assert.Equal(t, "abcdefg", Hyphenate("abcdefg", 6, 0))
+	assert.Equal(t, "\x1b[1mab-\ncd\x1b[0m", Hyphenate("\x1b[1mabcd\x1b[0m", 3, 2))
+
+	lines, _ := Wrap(Hyphenate("x hyphenation", 6, 2), 6)
+	assert.Equal(t, []string{"x", "hyphe-", "nation"}, lines)
+}
+
+func TestHyphenatedMinContent(t *testing.T) {
+	assert.Equal(t, 3, HyphenatedMinContent("a hyphenation", 2))
+	assert.Equal(t, 3, HyphenatedMinContent("abc", 2))
+	assert.Equal(t, 11, HyphenatedMinContent("hyphenation", 0))
+	assert.Equal(t, 2, HyphenatedMinContent("私 ab", 1))
+}
+
+func TestMarkBreaks(t *testing.T) {
+	comma := func(r rune) bool { return r == ',' }
+	assert.Equal(t, "a,\u200bb", MarkBreaks("a,b", comma))
+	assert.Equal(t, "a,b", MarkBreaks("a,b", nil))
+	assert.Equal(t, "\x1b[1m,\u200b\x1b[0m", MarkBreaks("\x1b[1m,\x1b[0m", comma))
+	assert.Equal(t, 4, MinContent(MarkBreaks("abc,def,g", comma)))
+
+	lines, _ := Wrap(MarkBreaks("abc,def,g", comma), 6)
+	assert.Equal(t, []string{"abc,", "def,g"}, lines)
+	lines, _ = Wrap(MarkBreaks("abc,def,g", comma), 10)
+	assert.Equal(t, []string{"abc,def,g"}, lines)
+}
+
+func TestWrapMarked(t *testing.T) {
+	lines, err := WrapMarked("a long line of text", 8, "> ")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a long", "> line", "> of", "> text"}, lines)
+
+	lines, _ = WrapMarked("short", 8, "> ")
+	assert.Equal(t, []string{"short"}, lines)
+	lines, _ = WrapMarked("abc def", 2, "> ")
+	assert.Equal(t, []string{"ab", "c", "de", "f"}, lines)
+}
+
+func TestWrapIndent(t *testing.T) {
+	lines, err := WrapIndent("some text to wrap", 9, "├─ ", "│  ")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"├─ some", "│  text", "│  to", "│  wrap"}, lines)
+
+	lines, _ = WrapIndent("short", 9, "└─ ", "   ")
+	assert.Equal(t, []string{"└─ short"}, lines)
+}
+
+func TestCloseStyles(t *testing.T) {
+	assert.Equal(t, "plain", CloseStyles("plain"))
+	assert.Equal(t, "\x1b[31mred\x1b[0m", CloseStyles("\x1b[31mred\x1b[0m"))
+	assert.Equal(t, "\x1b[31mred\x1b[0m", CloseStyles("\x1b[31mred"))
+}
+
+func TestStripEscapes(t *testing.T) {
+	assert.Equal(t, "plain", StripEscapes("plain"))
+	assert.Equal(t, "red", StripEscapes("\x1b[31mred\x1b[0m"))
+	assert.Equal(t, "link", StripEscapes("\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\"))
+	assert.Equal(t, "link", StripEscapes("\x1b]8;;https://example.com\alink\x1b]8;;\a"))
+	assert.Equal(t, "ab", StripEscapes("a\x1b[2Kb\x1b[1"))
+}
+
+func TestTrimRight(t *testing.T) {
+	assert.Equal(t, "  a b", TrimRight("  a b \t "))
+	assert.Equal(t, "\x1b[31ma\x1b[0m", TrimRight("\x1b[31ma  \x1b[0m"))
+	assert.Equal(t, "a\x1b[1m\x1b[0m", TrimRight("a \x1b[1m \x1b[0m"))
+}
+
+func TestHighlight(t *testing.T) {
+	re := regexp.MustCompile("ab")
+	assert.Equal(t, "xyz", Highlight("xyz", re, "<", ">"))
+	assert.Equal(t, "<ab>c<ab>", Highlight("abcab", re, "<", ">"))
+	assert.Equal(t, "é<ab>", Highlight("éab", re, "<", ">"))
+	// the style of the text is restored after the match
+	assert.Equal(t, "\x1b[31mx<ab>\x1b[31my\x1b[0m", Highlight("\x1b[31mxaby\x1b[0m", re, "<", ">"))
+	// the match ignores the escapes
+	assert.Equal(t, "<a\x1b[1mb>\x1b[1mc", Highlight("a\x1b[1mbc", re, "<", ">"))
+}
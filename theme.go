@@ -0,0 +1,91 @@
+package flexwriter
+
+import "github.com/fatih/color"
+
+// Theme bundles the look of a table: the borders drawn by its decorator, and
+// the colors of its borders, of its header and section rows, and of its body
+// rows. It is applied with [Writer.SetTheme]; the predefined themes, e.g.
+// [RoundedTheme], can be modified before being applied, e.g. to change one of
+// their colors.
+type Theme struct {
+	// Decorator draws the borders of the table; if nil, the columns are
+	// separated by 2 spaces, as by default.
+	Decorator Decorator
+	// BorderColor, if not nil, is the color of the borders, see
+	// [ColorizeDecorator].
+	BorderColor *color.Color
+	// HeaderStyle, if not nil, is the color of the header rows, see
+	// [Writer.SetHeaderStyle].
+	HeaderStyle *color.Color
+	// SectionStyle, if not nil, is the color of the titles of the section
+	// rows, see [Writer.SetSectionStyle].
+	SectionStyle *color.Color
+	// RowStyles are the colors of the body rows, alternately, see
+	// [Writer.SetAlternatingRows].
+	RowStyles [2]*color.Color
+}
+
+// SetTheme sets the decorator of the flex writer and the styles of its rows
+// from the theme, replacing those previously set with e.g.
+// [Writer.SetDecorator] or [Writer.SetHeaderStyle].
+func (w *Writer) SetTheme(theme Theme) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	deco := theme.Decorator
+	if deco == nil {
+		deco = GapDecorator{Gap: "  "}
+	}
+	if theme.BorderColor != nil {
+		deco = ColorizeDecorator(deco, theme.BorderColor)
+	}
+	w.deco = deco
+	w.headerStyle = newStyle(theme.HeaderStyle)
+	w.sectionStyle = newStyle(theme.SectionStyle)
+	w.rowStyles = []style{newStyle(theme.RowStyles[0]), newStyle(theme.RowStyles[1])}
+}
+
+// PlainTheme is the default look: the columns are separated by 2 spaces, and
+// the header is bold.
+func PlainTheme() Theme {
+	return Theme{HeaderStyle: color.New(color.Bold)}
+}
+
+// RoundedTheme is a table with rounded corners and a bold header.
+func RoundedTheme() Theme {
+	return Theme{
+		Decorator:    RoundedTableDecorator(),
+		HeaderStyle:  color.New(color.Bold),
+		SectionStyle: color.New(color.Bold),
+	}
+}
+
+// DoubleTheme is a table with double borders and a bold header.
+func DoubleTheme() Theme {
+	return Theme{
+		Decorator:    DoubleTableDecorator(),
+		HeaderStyle:  color.New(color.Bold),
+		SectionStyle: color.New(color.Bold),
+	}
+}
+
+// MinimalTheme is a table without borders, with an underlined bold header.
+func MinimalTheme() Theme {
+	return Theme{
+		Decorator:    MinimalTableDecorator(),
+		HeaderStyle:  color.New(color.Bold),
+		SectionStyle: color.New(color.Bold),
+	}
+}
+
+// ColorfulTheme is a table with rounded cyan borders, a bold cyan header,
+// yellow section titles and striped body rows.
+func ColorfulTheme() Theme {
+	return Theme{
+		Decorator:    RoundedTableDecorator(),
+		BorderColor:  color.New(color.FgCyan),
+		HeaderStyle:  color.New(color.Bold, color.FgCyan),
+		SectionStyle: color.New(color.Bold, color.FgYellow),
+		RowStyles:    [2]*color.Color{nil, color.New(color.BgHiBlack)},
+	}
+}
@@ -0,0 +1,79 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemes(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	write := func(theme Theme) string {
+		var buf bytes.Buffer
+		writer := New()
+		writer.SetOutput(&buf)
+		writer.SetTheme(theme)
+		writer.SetHeaders("name", "qty")
+		writer.WriteRow("pear", 3)
+		writer.WriteRow("fig", 12)
+		writer.Flush()
+		return buf.String()
+	}
+
+	assert.Equal(t, ""+
+		"name  qty\n"+
+		"pear  3\n"+
+		"fig   12\n", write(PlainTheme()))
+	assert.Equal(t, ""+
+		"╭──────┬─────╮\n"+
+		"│ name │ qty │\n"+
+		"╞══════╪═════╡\n"+
+		"│ pear │ 3   │\n"+
+		"├──────┼─────┤\n"+
+		"│ fig  │ 12  │\n"+
+		"╰──────┴─────╯\n", write(RoundedTheme()))
+	assert.Equal(t, ""+
+		"╔══════╦═════╗\n"+
+		"║ name ║ qty ║\n"+
+		"╠══════╬═════╣\n"+
+		"║ pear ║ 3   ║\n"+
+		"╠══════╬═════╣\n"+
+		"║ fig  ║ 12  ║\n"+
+		"╚══════╩═════╝\n", write(DoubleTheme()))
+	assert.Equal(t, ""+
+		"name  qty\n"+
+		"─────────\n"+
+		"pear  3\n"+
+		"fig   12\n", write(MinimalTheme()))
+}
+
+func TestThemeColors(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetTheme(Theme{
+		HeaderStyle:  color.New(color.Bold),
+		SectionStyle: color.New(color.FgYellow),
+		RowStyles:    [2]*color.Color{nil, color.New(color.BgBlue)},
+	})
+	writer.SetHeaders("name", "qty")
+	writer.WriteSection("fruits")
+	writer.WriteRow("pear", 3)
+	writer.WriteRow("fig", 12)
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"\x1b[1mname\x1b[22m  \x1b[1mqty\x1b[22m\n"+
+		"\x1b[33mfruits\x1b[0m\n"+
+		"pear  3\n"+
+		"\x1b[44mfig \x1b[0m  \x1b[44m12 \x1b[0m\n", buf.String())
+}
@@ -0,0 +1,130 @@
+package flexwriter
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/hchargois/flexwriter/flex"
+)
+
+// Tree columns render a hierarchy: the content of each cell is prefixed with
+// box drawing branches ("├─ ", "└─ ", "│  ") according to the depth of its row,
+// as set by [Writer.WriteTreeRow], like the output of the tree command. The
+// branches count in the width of the column; if the content is wrapped, the
+// branches are continued on the following lines.
+//
+// Other than that, a Tree column is like a left-aligned [Shrinkable].
+type Tree struct {
+	// Min is the minimum width of the column, including the branches. If the
+	// content is smaller, the column will be padded.
+	Min int
+	// Max is the maximum width of the column, including the branches; if the
+	// content is longer it will be wrapped. If Max is 0, then there is no
+	// maximum width.
+	Max int
+	// Wrap is how the content is wrapped when it is wider than the column;
+	// default is [WrapWord].
+	Wrap WrapMode
+	// Style is the color of the content of the cells, see [Column].
+	Style *color.Color
+	// Order is the position of the column in the output, see [Column].
+	Order int
+	// Priority is the importance of the column, see [Column].
+	Priority int
+}
+
+func (t Tree) flex() flexItem {
+	if t.Max != 0 && t.Min > t.Max {
+		t.Min = t.Max
+	}
+	return flexItem{
+		Item: flex.Item{
+			Basis:  Auto,
+			Shrink: weightScale,
+			Min:    t.Min,
+			Max:    t.Max,
+			Order:  t.Order,
+		},
+		tree:     true,
+		priority: t.Priority,
+		style:    newStyle(t.Style),
+		wrap:     t.Wrap,
+	}
+}
+
+// WriteTreeRow is like [Writer.WriteRow], but the row is at the given depth
+// in the hierarchy rendered by the [Tree] columns: 0 for the roots, 1 for
+// their children, etc. A row is the child of the closest row above it with a
+// lower depth.
+func (w *Writer) WriteTreeRow(depth int, cells ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if depth < 0 {
+		depth = 0
+	}
+	w.writeRow(cells...)
+	w.rows[len(w.rows)-1].depth = depth
+	w.streamRows()
+}
+
+// computeTreePrefixes sets the branches of the rows, for the first line and
+// for the continuation lines of their cells in the tree columns.
+func (w *Writer) computeTreePrefixes() {
+	// cont[k] is whether the rows below the current one continue the level k,
+	// i.e. have a row at depth k before any row of a lower depth
+	var cont []bool
+	// depth of the row below the current one
+	below := -1
+	for i := len(w.rows) - 1; i >= 0; i-- {
+		r := &w.rows[i]
+		if r.rule {
+			continue
+		}
+		for len(cont) <= r.depth {
+			cont = append(cont, false)
+		}
+
+		var first, next strings.Builder
+		for k := 1; k <= r.depth; k++ {
+			switch {
+			case k < r.depth && cont[k]:
+				first.WriteString("│  ")
+				next.WriteString("│  ")
+			case k < r.depth:
+				first.WriteString("   ")
+				next.WriteString("   ")
+			case cont[k]:
+				first.WriteString("├─ ")
+				next.WriteString("│  ")
+			default:
+				first.WriteString("└─ ")
+				next.WriteString("   ")
+			}
+		}
+		if below > r.depth {
+			// connect the row to its children
+			next.WriteString("│  ")
+		}
+		r.tree = [2]string{first.String(), next.String()}
+
+		below = r.depth
+		cont[r.depth] = true
+		for k := r.depth + 1; k < len(cont); k++ {
+			cont[k] = false
+		}
+	}
+}
+
+// hasTree returns whether any of the visible columns is a tree column.
+func (w *Writer) hasTree() bool {
+	if w.defaultCol.tree && !w.omitDefault {
+		return true
+	}
+	for _, col := range w.columns {
+		if col.tree {
+			return true
+		}
+	}
+	return false
+}
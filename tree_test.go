@@ -0,0 +1,36 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	var buf bytes.Buffer
+	writer := New()
+	writer.SetOutput(&buf)
+	writer.SetWidth(30)
+	writer.SetColumns(Tree{}, Shrinkable{})
+
+	writer.WriteTreeRow(0, "flexwriter", "root")
+	writer.WriteTreeRow(1, "flex", "the flexbox algorithm")
+	writer.WriteTreeRow(2, "flex.go", "")
+	writer.WriteTreeRow(1, "textutil", "")
+	writer.WriteTreeRow(2, "textutil.go", "")
+	writer.WriteTreeRow(2, "textutil_test.go", "")
+	writer.WriteTreeRow(0, "README.md", "")
+	writer.Flush()
+
+	assert.Equal(t, ""+
+		"flexwriter              root\n"+
+		"├─ flex                 the\n"+
+		"│  │                    flexbox\n"+
+		"│  │                    algorithm\n"+
+		"│  └─ flex.go           \n"+
+		"└─ textutil             \n"+
+		"   ├─ textutil.go       \n"+
+		"   └─ textutil_test.go  \n"+
+		"README.md               \n", buf.String())
+}
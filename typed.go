@@ -0,0 +1,80 @@
+package flexwriter
+
+// TypedColumn is a column of a [TypedWriter] of items of type T.
+type TypedColumn[T any] struct {
+	// Title is the header of the column; if all the titles are empty, there
+	// is no header row.
+	Title string
+	// Get extracts the value of the cell of the column from an item; it is
+	// converted like the cells given to [Writer.WriteRow].
+	Get func(item T) any
+	// Column is the configuration of the column; if nil, the default column
+	// configuration is used.
+	Column Column
+}
+
+// TypedWriter is a flex writer whose rows are items of type T, e.g. the
+// elements of a slice of structs, and whose cells are extracted by its
+// columns, so that the values always match the columns. As it embeds a
+// [Writer], it is configured and flushed like any flex writer.
+type TypedWriter[T any] struct {
+	*Writer
+	columns []TypedColumn[T]
+}
+
+// NewTyped creates a new typed writer with the given columns, configured like
+// a writer returned by [New] otherwise.
+func NewTyped[T any](columns ...TypedColumn[T]) *TypedWriter[T] {
+	writer := &TypedWriter[T]{
+		Writer:  New(),
+		columns: append([]TypedColumn[T](nil), columns...),
+	}
+
+	cols := make([]Column, len(columns))
+	var headers []any
+	for i, col := range columns {
+		cols[i] = col.Column
+		if col.Title != "" {
+			headers = make([]any, len(columns))
+		}
+	}
+	if headers != nil {
+		for i, col := range columns {
+			headers[i] = col.Title
+		}
+		writer.SetHeaders(headers...)
+	}
+	writer.SetColumns(cols...)
+	return writer
+}
+
+// Add writes a row with the cells extracted from the item.
+func (w *TypedWriter[T]) Add(item T) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeRow(w.cells(item)...)
+	w.streamRows()
+}
+
+// AddAll writes a row for each of the items.
+func (w *TypedWriter[T]) AddAll(items []T) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, item := range items {
+		w.writeRow(w.cells(item)...)
+	}
+	w.streamRows()
+}
+
+// cells returns the cells extracted from an item.
+func (w *TypedWriter[T]) cells(item T) []any {
+	cells := make([]any, len(w.columns))
+	for i, col := range w.columns {
+		if col.Get != nil {
+			cells[i] = col.Get(item)
+		}
+	}
+	return cells
+}
@@ -0,0 +1,32 @@
+package flexwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedWriter(t *testing.T) {
+	type user struct {
+		name string
+		age  int
+	}
+
+	var buf bytes.Buffer
+	writer := NewTyped(
+		TypedColumn[user]{Title: "Name", Get: func(u user) any { return u.name }},
+		TypedColumn[user]{Title: "Age", Get: func(u user) any { return u.age }, Column: Rigid{Align: Right}},
+	)
+	writer.SetOutput(&buf)
+	writer.SetHeaderStyle(nil)
+
+	writer.Add(user{"alice", 31})
+	writer.AddAll([]user{{"bob", 7}})
+	assert.NoError(t, writer.Flush())
+
+	assert.Equal(t, ""+
+		"Name   Age\n"+
+		"alice   31\n"+
+		"bob      7\n", buf.String())
+}